@@ -0,0 +1,28 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# The password is read from the PIHOLE_PASSWORD environment variable
+provider "pihole" {
+  url = "http://localhost:8080"
+}
+
+# Fail the plan when a domain meant to be allowed is still blocked
+data "pihole_domain_search" "login" {
+  domain = "login.example.com"
+
+  lifecycle {
+    postcondition {
+      condition     = !self.blocked
+      error_message = "login.example.com is blocked by ${self.blocked_by}: ${jsonencode(concat(self.exact, self.regex))}"
+    }
+  }
+}
+
+output "blocking_adlists" {
+  value = [for list in data.pihole_domain_search.login.adlists : list.address if list.enabled && list.type == "block"]
+}
@@ -0,0 +1,26 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# The password is read from the PIHOLE_PASSWORD environment variable
+provider "pihole" {
+  url = "http://localhost:8080"
+}
+
+# Smoke test: the test client must have been blocked from ads.example.com
+# during the last 15 minutes
+data "pihole_queries" "blocked" {
+  from   = timeadd(timestamp(), "-15m")
+  client = "192.168.1.50"
+  domain = "ads.example.com"
+  status = "blocked"
+  limit  = 10
+}
+
+output "blocked_queries" {
+  value = length(data.pihole_queries.blocked.queries)
+}
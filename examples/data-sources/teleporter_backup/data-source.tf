@@ -0,0 +1,21 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# The password is read from the PIHOLE_PASSWORD environment variable
+provider "pihole" {
+  url = "http://localhost:8080"
+}
+
+# Snapshot the configuration before applying risky changes
+data "pihole_teleporter_backup" "before_apply" {
+  output_path = "${path.root}/pihole-backup.zip"
+}
+
+output "backup_sha256" {
+  value = data.pihole_teleporter_backup.before_apply.sha256
+}
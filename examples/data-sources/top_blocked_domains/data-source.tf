@@ -0,0 +1,21 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# The password is read from the PIHOLE_PASSWORD environment variable
+provider "pihole" {
+  url = "http://localhost:8080"
+}
+
+# Candidates for review: the blocked domains clients ask for the most
+data "pihole_top_blocked_domains" "this" {
+  count = 20
+}
+
+output "allowlist_candidates" {
+  value = [for item in data.pihole_top_blocked_domains.this.domains : item.domain]
+}
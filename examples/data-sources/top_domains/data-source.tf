@@ -0,0 +1,20 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# The password is read from the PIHOLE_PASSWORD environment variable
+provider "pihole" {
+  url = "http://localhost:8080"
+}
+
+data "pihole_top_domains" "this" {
+  count = 5
+}
+
+output "top_domains" {
+  value = { for item in data.pihole_top_domains.this.domains : item.domain => item.count }
+}
@@ -0,0 +1,18 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# The password is read from the PIHOLE_PASSWORD environment variable
+provider "pihole" {
+  url = "http://localhost:8080"
+}
+
+data "pihole_upstreams" "this" {}
+
+output "upstreams" {
+  value = { for item in data.pihole_upstreams.this.upstreams : item.ip => item.count }
+}
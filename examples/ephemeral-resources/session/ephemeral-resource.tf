@@ -0,0 +1,29 @@
+terraform {
+  required_version = ">= 1.10"
+
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+    http = {
+      source = "hashicorp/http"
+    }
+  }
+}
+
+# The password is read from the PIHOLE_PASSWORD environment variable
+provider "pihole" {
+  url = "http://localhost:8080"
+}
+
+ephemeral "pihole_session" "this" {}
+
+# Call an endpoint the provider does not cover with the session, which is
+# logged out at the end of the run
+ephemeral "http" "dhcp_leases" {
+  url = "${ephemeral.pihole_session.this.url}/dhcp/leases"
+
+  request_headers = {
+    "X-FTL-SID" = ephemeral.pihole_session.this.sid
+  }
+}
@@ -0,0 +1,12 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# nas.lan
+output "nas_fqdn" {
+  value = provider::pihole::fqdn("nas", "lan")
+}
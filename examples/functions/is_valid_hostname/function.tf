@@ -0,0 +1,16 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+variable "hosts" {
+  type = map(string)
+
+  validation {
+    condition     = alltrue([for host in keys(var.hosts) : provider::pihole::is_valid_hostname(host)])
+    error_message = "Each host must be a valid hostname."
+  }
+}
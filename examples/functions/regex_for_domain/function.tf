@@ -0,0 +1,12 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# (\.|^)doubleclick\.net$
+output "doubleclick_regex" {
+  value = provider::pihole::regex_for_domain("doubleclick.net", true)
+}
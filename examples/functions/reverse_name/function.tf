@@ -0,0 +1,18 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# The password is read from the PIHOLE_PASSWORD environment variable
+provider "pihole" {
+  url = "http://localhost:8080"
+}
+
+resource "pihole_dns_extra_record" "nas_ptr" {
+  type   = "PTR"
+  name   = provider::pihole::reverse_name("192.168.1.20")
+  target = "nas.lan"
+}
@@ -6,6 +6,18 @@ terraform {
   }
 }
 
-provider "pihole" {}
+# Credentials are read from PIHOLE_TOKEN or PIHOLE_PASSWORD when not set here
+provider "pihole" {
+  url = "http://localhost:8080"
 
-resource "pihole_dnsrecord" "example" {}
\ No newline at end of file
+  # Read the web password from a Docker or Kubernetes secret
+  password_file = "/run/secrets/pihole_password"
+
+  # Or from a password manager
+  # credential_command = ["op", "read", "op://infra/pihole/password"]
+}
+
+resource "pihole_dnsrecord" "example" {
+  domain = "example.lan"
+  ip     = "192.168.1.10"
+}
@@ -0,0 +1,2 @@
+# The adlists are imported with their type, block or allow, into urls
+terraform import pihole_adlists.blocklists block
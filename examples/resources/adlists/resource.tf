@@ -0,0 +1,30 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# The password is read from the PIHOLE_PASSWORD environment variable. Gravity
+# updates may outlast the request_timeout of the provider.
+provider "pihole" {
+  url = "http://localhost:8080"
+}
+
+# Blocklists read from a file, gravity being updated once when they change
+resource "pihole_adlists" "blocklists" {
+  source_file = "${path.module}/blocklists.txt"
+  comment     = "Managed by Terraform"
+}
+
+resource "pihole_adlists" "allowlists" {
+  type = "allow"
+  urls = [
+    "https://raw.githubusercontent.com/anudeepND/whitelist/master/domains/whitelist.txt",
+  ]
+}
+
+output "blocklists_added" {
+  value = pihole_adlists.blocklists.added
+}
@@ -6,14 +6,15 @@ terraform {
   }
 }
 
-# search env variables
+# The password is read from the PIHOLE_PASSWORD environment variable
 provider "pihole" {
-  url   = "http://localhost:8080/admin/api.php"
-  token = "96cf46f9e9312ea9ad00f5f9e63b25643f701246357068549a6c2ea3d163bf1e"
+  url = "http://localhost:8080"
 }
 
+# The alias is answered exactly as written: use the full name clients query,
+# such as test.lan, rather than a short host name like test
 resource "pihole_cname" "example-2" {
-  domain = "test"
+  domain = "test.lan"
   target = "test1.example.com"
 }
 
@@ -0,0 +1,2 @@
+# The configuration is imported with the comma separated keys to manage
+terraform import pihole_config.this 'dns.bogusPriv,webserver.session.timeout'
@@ -0,0 +1,23 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# The password is read from the PIHOLE_PASSWORD environment variable
+provider "pihole" {
+  url = "http://localhost:8080"
+}
+
+# Only the listed keys are managed, the rest of the configuration is left alone
+resource "pihole_config" "this" {
+  values = {
+    "dns.bogusPriv"             = "true"
+    "webserver.session.timeout" = "3600"
+    "dns.upstreams"             = jsonencode(["9.9.9.9", "149.112.112.112"])
+  }
+
+  restore_on_destroy = true
+}
@@ -0,0 +1,2 @@
+# Extra DNS records are imported with the dnsmasq option declaring them
+terraform import pihole_dns_extra_record.mail 'mx-host=example.com,mail.example.com,10'
@@ -0,0 +1,42 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# The password is read from the PIHOLE_PASSWORD environment variable
+provider "pihole" {
+  url = "http://localhost:8080"
+}
+
+# LDAP and Kerberos discovery for the domain controller
+resource "pihole_dns_extra_record" "ldap" {
+  type     = "SRV"
+  name     = "_ldap._tcp.example.com"
+  target   = "dc1.example.com"
+  port     = 389
+  priority = 0
+  weight   = 100
+}
+
+resource "pihole_dns_extra_record" "kerberos" {
+  type   = "SRV"
+  name   = "_kerberos._udp.example.com"
+  target = "dc1.example.com"
+  port   = 88
+}
+
+resource "pihole_dns_extra_record" "mail" {
+  type     = "MX"
+  name     = "example.com"
+  target   = "mail.example.com"
+  priority = 10
+}
+
+resource "pihole_dns_extra_record" "spf" {
+  type = "TXT"
+  name = "example.com"
+  text = "v=spf1 mx -all"
+}
@@ -1,2 +1,5 @@
 # Import existing FQDN
-terraform import pihole_dnsrecord.example "test.pasfastoche.lan"
\ No newline at end of file
+terraform import pihole_dnsrecord.example "test.pasfastoche.lan"
+
+# Import one of the records of a FQDN having several
+terraform import pihole_dnsrecord.example "test.pasfastoche.lan,192.168.1.10"
@@ -6,10 +6,9 @@ terraform {
   }
 }
 
-# search env variables
+# The password is read from the PIHOLE_PASSWORD environment variable
 provider "pihole" {
-  url   = "http://localhost:8080/admin/api.php"
-  token = "96cf46f9e9312ea9ad00f5f9e63b25643f701246357068549a6c2ea3d163bf1e"
+  url = "http://localhost:8080"
 }
 
 resource "pihole_dnsrecord" "example-1" {
@@ -21,3 +20,10 @@ resource "pihole_dnsrecord" "example-2" {
   domain = "test2.example.com"
   ip     = "2.2.2.2"
 }
+
+# Also answer reverse lookups of 192.168.1.20, as SSH and Kerberos expect
+resource "pihole_dnsrecord" "lab" {
+  domain     = "lab1.example.com"
+  ip         = "192.168.1.20"
+  create_ptr = true
+}
@@ -0,0 +1,3 @@
+# The FTL settings are adopted whatever the import ID, the next apply writing
+# the configured ones
+terraform import pihole_ftl_settings.this ftl
@@ -0,0 +1,21 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# The password is read from the PIHOLE_PASSWORD environment variable
+provider "pihole" {
+  url = "http://localhost:8080"
+}
+
+# Settings left unset, such as rate_limit_interval, are not managed: they keep
+# their current value, also when the resource is destroyed
+resource "pihole_ftl_settings" "this" {
+  rate_limit_count = 5000
+  privacy_level    = 1
+  query_logging    = true
+  max_db_days      = 30
+}
@@ -0,0 +1,2 @@
+# The members are imported with the ID of the group
+terraform import pihole_group_assignment.kids 1
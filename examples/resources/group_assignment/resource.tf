@@ -0,0 +1,22 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# The password is read from the PIHOLE_PASSWORD environment variable
+provider "pihole" {
+  url = "http://localhost:8080"
+}
+
+# The kids group applies the gaming blocklist and the social media regex to
+# the tablets, and nothing else: items added to the group in the web
+# interface show in the next plan and are removed on apply
+resource "pihole_group_assignment" "kids" {
+  group_id   = 1
+  adlist_ids = [3]
+  domain_ids = [12, 13]
+  client_ids = [4, 5]
+}
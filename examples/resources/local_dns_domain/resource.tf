@@ -0,0 +1,31 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# The password is read from the PIHOLE_PASSWORD environment variable
+provider "pihole" {
+  url = "http://localhost:8080"
+}
+
+resource "pihole_local_dns_domain" "this" {
+  domain        = "lan"
+  expand_hosts  = true
+  domain_needed = true
+  bogus_priv    = true
+}
+
+# With expand_hosts, nas also answers as nas.lan, given by fqdn
+resource "pihole_dnsrecord" "nas" {
+  domain = "nas"
+  ip     = "192.168.1.20"
+
+  depends_on = [pihole_local_dns_domain.this]
+}
+
+output "nas_fqdn" {
+  value = pihole_dnsrecord.nas.fqdn
+}
@@ -0,0 +1,18 @@
+terraform {
+  required_providers {
+    pihole = {
+      source = "localhost/dev/pihole"
+    }
+  }
+}
+
+# The password is read from the PIHOLE_PASSWORD environment variable
+provider "pihole" {
+  url = "http://localhost:8080"
+}
+
+# Seed a new Pihole with the lists and groups of a golden configuration
+resource "pihole_teleporter_restore" "golden" {
+  archive_path = "${path.module}/golden-teleporter.zip"
+  sections     = ["adlists", "domains", "groups", "clients"]
+}
@@ -0,0 +1,285 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// gravityTimeout bounds a gravity update, which downloads every adlist and
+// outlasts the request timeout of the provider. Variable so tests do not have
+// to wait.
+var gravityTimeout = 30 * time.Minute
+
+// adlistTypes are the types of adlists: blocklists and allowlists.
+var adlistTypes = []string{"block", "allow"}
+
+// adlistCommentRegexp matches the comment of a line of an adlist source file.
+var adlistCommentRegexp = regexp.MustCompile(`(^|\s)#.*$`)
+
+// adlistChanges are the addresses of the adlists added and removed by a
+// reconcile, and of the adlists left in place.
+type adlistChanges struct {
+	Added     []string
+	Removed   []string
+	Unchanged []string
+}
+
+// Changed reports whether adlists are added or removed.
+func (c adlistChanges) Changed() bool {
+	return len(c.Added)+len(c.Removed) > 0
+}
+
+// diffAdlists returns the changes turning the current addresses into the
+// desired ones, each sorted.
+func diffAdlists(current []string, desired []string) adlistChanges {
+	have := map[string]bool{}
+	for _, address := range current {
+		have[address] = true
+	}
+
+	changes := adlistChanges{Added: []string{}, Removed: []string{}, Unchanged: []string{}}
+	want := map[string]bool{}
+	for _, address := range desired {
+		if want[address] {
+			continue
+		}
+		want[address] = true
+		if have[address] {
+			changes.Unchanged = append(changes.Unchanged, address)
+		} else {
+			changes.Added = append(changes.Added, address)
+		}
+	}
+	for address := range have {
+		if !want[address] {
+			changes.Removed = append(changes.Removed, address)
+		}
+	}
+
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Unchanged)
+
+	return changes
+}
+
+// checkAdlistAddress checks that an adlist address is an http, https or file
+// URL, as gravity downloads.
+func checkAdlistAddress(address string) error {
+	u, err := url.Parse(address)
+	if err != nil {
+		return fmt.Errorf("%q is not a URL: %w", address, err)
+	}
+
+	switch {
+	case u.Scheme == "http" || u.Scheme == "https":
+		if u.Host == "" {
+			return fmt.Errorf("%q has no host", address)
+		}
+	case u.Scheme == "file":
+		if u.Path == "" {
+			return fmt.Errorf("%q has no path", address)
+		}
+	default:
+		return fmt.Errorf("%q is not an http, https or file URL", address)
+	}
+
+	return nil
+}
+
+// parseAdlistSource returns the addresses of a source file holding one URL
+// per line. Empty lines are skipped, and comments start with # at the
+// beginning of a line or after a blank.
+func parseAdlistSource(source string) ([]string, error) {
+	var addresses []string
+	scanner := bufio.NewScanner(strings.NewReader(source))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(adlistCommentRegexp.ReplaceAllString(scanner.Text(), ""))
+		if line == "" {
+			continue
+		}
+
+		if err := checkAdlistAddress(line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		addresses = append(addresses, line)
+	}
+
+	return addresses, scanner.Err()
+}
+
+// GetAdlists returns the sorted addresses of the adlists of a type.
+func (c *piholeClient) GetAdlists(ctx context.Context, listType string) ([]string, error) {
+	api, err := c.v6("pihole_adlists")
+	if err != nil {
+		return nil, err
+	}
+
+	return api.getAdlists(ctx, listType)
+}
+
+// SetAdlists reconciles the adlists of a type with the given addresses, in a
+// single batch of additions and a single batch of removals. Added adlists
+// get the comment.
+func (c *piholeClient) SetAdlists(ctx context.Context, listType string, addresses []string, comment string) (adlistChanges, error) {
+	api, err := c.v6("pihole_adlists")
+	if err != nil {
+		return adlistChanges{}, err
+	}
+
+	// Retries read the adlists again and only send the changes left, so a
+	// write whose outcome is unknown is simply sent again
+	var changes adlistChanges
+	err = c.write(ctx,
+		func() error {
+			current, err := api.getAdlists(ctx, listType)
+			if err != nil {
+				return err
+			}
+
+			changes = diffAdlists(current, addresses)
+			if err := api.addAdlists(ctx, listType, changes.Added, comment); err != nil {
+				return err
+			}
+
+			return api.deleteAdlists(ctx, listType, changes.Removed)
+		},
+		func() (bool, error) { return false, nil },
+	)
+
+	return changes, err
+}
+
+// DeleteAdlists removes the adlists of a type among addresses in a single
+// batch, and returns the addresses removed.
+func (c *piholeClient) DeleteAdlists(ctx context.Context, listType string, addresses []string) ([]string, error) {
+	api, err := c.v6("pihole_adlists")
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	err = c.write(ctx,
+		func() error {
+			current, err := api.getAdlists(ctx, listType)
+			if err != nil {
+				return err
+			}
+
+			// The adlists already gone are left out of the batch
+			removed = diffAdlists(current, addresses).Unchanged
+			return api.deleteAdlists(ctx, listType, removed)
+		},
+		func() (bool, error) { return false, nil },
+	)
+
+	return removed, err
+}
+
+// UpdateGravity downloads the adlists again and rebuilds the gravity
+// database, waiting for the update to complete. The update is not retried,
+// nor does it hold the writes back while it runs: a failed update is left to
+// the next apply.
+func (c *piholeClient) UpdateGravity(ctx context.Context) error {
+	api, err := c.v6("Gravity updates")
+	if err != nil {
+		return err
+	}
+
+	return api.updateGravity(ctx)
+}
+
+// getAdlists returns the sorted addresses of the adlists of a type.
+func (a *v6API) getAdlists(ctx context.Context, listType string) ([]string, error) {
+	items, err := a.getItems(ctx, listsTable)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := []string{}
+	for _, item := range items {
+		if item.Type == listType {
+			addresses = append(addresses, item.Address)
+		}
+	}
+	sort.Strings(addresses)
+
+	return addresses, nil
+}
+
+// addAdlists adds enabled adlists in the Default group.
+func (a *v6API) addAdlists(ctx context.Context, listType string, addresses []string, comment string) error {
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	body := map[string]interface{}{
+		"address": addresses,
+		"type":    listType,
+		"enabled": true,
+	}
+	if comment != "" {
+		body["comment"] = comment
+	}
+
+	var answer struct {
+		Processed struct {
+			Errors []struct {
+				Item  string `json:"item"`
+				Error string `json:"error"`
+			} `json:"errors"`
+		} `json:"processed"`
+	}
+	if err := a.do(ctx, http.MethodPost, "/lists?"+url.Values{"type": {listType}}.Encode(), body, &answer); err != nil {
+		return err
+	}
+
+	if len(answer.Processed.Errors) > 0 {
+		var failures []string
+		for _, failure := range answer.Processed.Errors {
+			failures = append(failures, failure.Item+": "+failure.Error)
+		}
+		return fmt.Errorf("could not add %s", strings.Join(failures, ", "))
+	}
+
+	return nil
+}
+
+// deleteAdlists removes adlists in a single batch.
+func (a *v6API) deleteAdlists(ctx context.Context, listType string, addresses []string) error {
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	items := make([]map[string]string, 0, len(addresses))
+	for _, address := range addresses {
+		items = append(items, map[string]string{"item": address, "type": listType})
+	}
+
+	return a.do(ctx, http.MethodPost, "/lists:batchDelete", items, nil)
+}
+
+// updateGravity runs a gravity update. Pihole streams its output until the
+// update completes, within gravityTimeout rather than the request timeout,
+// login included.
+func (a *v6API) updateGravity(ctx context.Context) error {
+	timeout := fmt.Errorf("gravity update did not complete within %s", gravityTimeout)
+	ctx, cancel := context.WithTimeoutCause(withoutRequestTimeout(ctx), gravityTimeout, timeout)
+	defer cancel()
+
+	var output []byte
+	err := a.do(ctx, http.MethodPost, "/action/gravity", nil, &output)
+	if err != nil && errors.Is(context.Cause(ctx), timeout) {
+		return timeout
+	}
+
+	return err
+}
@@ -0,0 +1,519 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &AdlistsResource{}
+	_ resource.ResourceWithConfigure      = &AdlistsResource{}
+	_ resource.ResourceWithImportState    = &AdlistsResource{}
+	_ resource.ResourceWithValidateConfig = &AdlistsResource{}
+	_ resource.ResourceWithModifyPlan     = &AdlistsResource{}
+)
+
+// NewAdlistsResource is a helper function to simplify the provider implementation.
+func NewAdlistsResource() resource.Resource {
+	return &AdlistsResource{}
+}
+
+// AdlistsResource is the resource implementation.
+type AdlistsResource struct {
+	client *piholeClient
+}
+
+// AdlistsResourceModel maps the resource schema data.
+type AdlistsResourceModel struct {
+	LastUpdated   types.String `tfsdk:"last_updated"`
+	URLs          types.Set    `tfsdk:"urls"`
+	SourceFile    types.String `tfsdk:"source_file"`
+	Type          types.String `tfsdk:"type"`
+	Comment       types.String `tfsdk:"comment"`
+	UpdateGravity types.Bool   `tfsdk:"update_gravity"`
+	// GravityPending records a gravity update that failed, run again by the
+	// next apply.
+	GravityPending types.Bool `tfsdk:"gravity_pending"`
+	Addresses      types.Set  `tfsdk:"addresses"`
+	Added          types.Set  `tfsdk:"added"`
+	Removed        types.Set  `tfsdk:"removed"`
+	Unchanged      types.Set  `tfsdk:"unchanged"`
+}
+
+// Metadata returns the resource type name.
+func (r *AdlistsResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_adlists"
+}
+
+// Schema defines the schema for the resource.
+func (r *AdlistsResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "All the Pihole adlists of a type, reconciled in a single batch of additions and a single " +
+			"batch of removals: adlists of the type missing from the configuration are removed, including the ones " +
+			"added outside of Terraform. The plan reports the adlists added, removed and unchanged, and gravity is " +
+			"updated once per apply when adlists change. The update may take up to 30 minutes, whatever the " +
+			"request_timeout of the provider; when it fails, the adlists are still saved and the update runs again on " +
+			"the next apply. Requires Pihole v6.",
+		Attributes: map[string]schema.Attribute{
+			"last_updated": schema.StringAttribute{
+				Description: "Timestamp of the last Terraform update of the adlists.",
+				Computed:    true,
+			},
+			"urls": schema.SetAttribute{
+				Description: "Addresses of the adlists, http, https or file URLs. Conflicts with source_file.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"source_file": schema.StringAttribute{
+				Description: "Path of a file holding the addresses of the adlists, one per line. Empty lines are " +
+					"skipped, and comments start with # at the beginning of a line or after a blank. The file is read " +
+					"at each plan. Conflicts with urls.",
+				Optional: true,
+			},
+			"type": schema.StringAttribute{
+				Description: "Type of the adlists, block or allow. Defaults to block.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("block"),
+				Validators: []validator.String{
+					oneOfValidator{values: adlistTypes},
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"comment": schema.StringAttribute{
+				Description: "Comment of the adlists added.",
+				Optional:    true,
+			},
+			"update_gravity": schema.BoolAttribute{
+				Description: "Whether to update gravity when adlists are added or removed. Defaults to true.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+			"gravity_pending": schema.BoolAttribute{
+				Description: "Whether the last gravity update failed or timed out, the next apply running it again.",
+				Computed:    true,
+			},
+			"addresses": schema.SetAttribute{
+				Description: "Addresses of the adlists, read from urls or source_file.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			"added": schema.SetAttribute{
+				Description: "Addresses of the adlists added by the plan.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			"removed": schema.SetAttribute{
+				Description: "Addresses of the adlists removed by the plan.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			"unchanged": schema.SetAttribute{
+				Description: "Addresses of the adlists left in place by the plan.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *AdlistsResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*piholeClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *piholeClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// ValidateConfig checks that the adlists come from either urls or
+// source_file, and the syntax of the URLs.
+func (r *AdlistsResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config AdlistsResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.URLs.IsNull() == config.SourceFile.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("urls"),
+			"Invalid Adlists Configuration",
+			"Exactly one of urls and source_file must be set.",
+		)
+		return
+	}
+
+	for _, element := range config.URLs.Elements() {
+		address, ok := element.(types.String)
+		if !ok || address.IsNull() || address.IsUnknown() {
+			continue
+		}
+		if err := checkAdlistAddress(address.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("urls").AtSetValue(address),
+				"Invalid Adlist Address",
+				err.Error()+".",
+			)
+		}
+	}
+}
+
+// addresses returns the addresses of the adlists configured in urls or
+// source_file, and false when they are not known yet.
+func (m *AdlistsResourceModel) addresses(ctx context.Context) ([]string, bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if m.URLs.IsUnknown() || m.SourceFile.IsUnknown() {
+		return nil, false, diags
+	}
+
+	if !m.SourceFile.IsNull() {
+		source, err := os.ReadFile(m.SourceFile.ValueString())
+		if err == nil {
+			var addresses []string
+			if addresses, err = parseAdlistSource(string(source)); err == nil {
+				return addresses, true, diags
+			}
+		}
+		diags.AddAttributeError(
+			path.Root("source_file"),
+			"Invalid Adlists Source File",
+			fmt.Sprintf("Could not read the adlists of %s: %s", m.SourceFile.ValueString(), err),
+		)
+		return nil, false, diags
+	}
+
+	for _, element := range m.URLs.Elements() {
+		if element.IsUnknown() {
+			return nil, false, diags
+		}
+	}
+	addresses := []string{}
+	diags.Append(m.URLs.ElementsAs(ctx, &addresses, false)...)
+
+	return addresses, !diags.HasError(), diags
+}
+
+// setChanges sets the addresses of the model, and the planned changes.
+func (m *AdlistsResourceModel) setChanges(ctx context.Context, changes adlistChanges) diag.Diagnostics {
+	var diags, d diag.Diagnostics
+	addresses := append(append([]string{}, changes.Added...), changes.Unchanged...)
+	m.Addresses, d = types.SetValueFrom(ctx, types.StringType, addresses)
+	diags.Append(d...)
+	m.Added, d = types.SetValueFrom(ctx, types.StringType, changes.Added)
+	diags.Append(d...)
+	m.Removed, d = types.SetValueFrom(ctx, types.StringType, changes.Removed)
+	diags.Append(d...)
+	m.Unchanged, d = types.SetValueFrom(ctx, types.StringType, changes.Unchanged)
+	diags.Append(d...)
+
+	return diags
+}
+
+// ModifyPlan reads the configured addresses and plans the adlists added,
+// removed and left in place.
+func (r *AdlistsResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan AdlistsResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	addresses, known, diags := plan.addresses(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The provider is not configured yet when its configuration is unknown
+	if !known || plan.Type.IsUnknown() || r.client == nil {
+		plan.GravityPending = types.BoolUnknown()
+		plan.Addresses = types.SetUnknown(types.StringType)
+		plan.Added = types.SetUnknown(types.StringType)
+		plan.Removed = types.SetUnknown(types.StringType)
+		plan.Unchanged = types.SetUnknown(types.StringType)
+		plan.LastUpdated = types.StringUnknown()
+		diags = resp.Plan.Set(ctx, &plan)
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	current, err := r.client.GetAdlists(ctx, plan.Type.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Pihole adlists",
+			"Could not read the adlists: "+err.Error(),
+		)
+		return
+	}
+
+	var pending types.Bool
+	if !req.State.Raw.IsNull() {
+		diags = req.State.GetAttribute(ctx, path.Root("gravity_pending"), &pending)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	changes := diffAdlists(current, addresses)
+	previous := plan.Addresses
+	resp.Diagnostics.Append(plan.setChanges(ctx, changes)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// A pending gravity update is run again, succeeding or not
+	if req.State.Raw.IsNull() || changes.Changed() || !plan.Addresses.Equal(previous) || pending.ValueBool() {
+		plan.LastUpdated = types.StringUnknown()
+		plan.GravityPending = types.BoolUnknown()
+	}
+
+	diags = resp.Plan.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// apply reconciles the adlists with the planned addresses, and records in
+// GravityPending whether gravity is to be updated: when adlists changed, or
+// when an earlier update failed.
+func (r *AdlistsResource) apply(ctx context.Context, plan *AdlistsResourceModel, pending bool) error {
+	addresses := []string{}
+	if diags := plan.Addresses.ElementsAs(ctx, &addresses, false); diags.HasError() {
+		return fmt.Errorf("could not read the addresses")
+	}
+
+	ctx = tflog.SetField(ctx, "type", plan.Type.ValueString())
+	changes, err := r.client.SetAdlists(ctx, plan.Type.ValueString(), addresses, plan.Comment.ValueString())
+	if err != nil {
+		return err
+	}
+
+	// A retried reconcile may find the changes already made
+	planned := len(plan.Added.Elements())+len(plan.Removed.Elements()) > 0
+	plan.GravityPending = types.BoolValue(plan.UpdateGravity.ValueBool() && (planned || changes.Changed() || pending))
+	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+
+	return nil
+}
+
+// updateGravity updates gravity when the model records the update as pending,
+// and clears it once done. A failed update stays pending.
+func (r *AdlistsResource) updateGravity(ctx context.Context, model *AdlistsResourceModel) error {
+	if !model.GravityPending.ValueBool() {
+		return nil
+	}
+
+	tflog.Info(ctx, "Updating gravity", map[string]any{"type": model.Type.ValueString()})
+	if err := r.client.UpdateGravity(ctx); err != nil {
+		return err
+	}
+	model.GravityPending = types.BoolValue(false)
+
+	return nil
+}
+
+// addGravityWarning reports a failed gravity update, left pending for the next
+// apply. The adlists are set, so the resource is saved rather than tainted.
+func addGravityWarning(diags *diag.Diagnostics, err error) {
+	diags.AddWarning(
+		"Pihole gravity update pending",
+		"The adlists are set, but gravity could not be updated: "+err.Error()+". The next apply updates gravity again.",
+	)
+}
+
+// Create reconciles the adlists.
+func (r *AdlistsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan AdlistsResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.apply(ctx, &plan, false); err != nil {
+		resp.Diagnostics.AddError(
+			"Error setting Pihole adlists",
+			"Could not set the adlists, unexpected error: "+err.Error(),
+		)
+		return
+	}
+	if err := r.updateGravity(ctx, &plan); err != nil {
+		addGravityWarning(&resp.Diagnostics, err)
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the adlists, so that adlists added or removed outside of
+// Terraform show in the next plan.
+func (r *AdlistsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state AdlistsResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	current, err := r.client.GetAdlists(ctx, state.Type.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Pihole adlists",
+			"Could not read the adlists: "+err.Error(),
+		)
+		return
+	}
+
+	// No adlist change is pending once applied, unlike a failed gravity
+	// update
+	resp.Diagnostics.Append(state.setChanges(ctx, diffAdlists(current, current))...)
+	if state.GravityPending.IsNull() {
+		state.GravityPending = types.BoolValue(false)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update reconciles the adlists.
+func (r *AdlistsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state AdlistsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.apply(ctx, &plan, state.GravityPending.ValueBool()); err != nil {
+		resp.Diagnostics.AddError(
+			"Error updating Pihole adlists",
+			"Could not update the adlists, unexpected error: "+err.Error(),
+		)
+		return
+	}
+	if err := r.updateGravity(ctx, &plan); err != nil {
+		addGravityWarning(&resp.Diagnostics, err)
+	}
+
+	diags := resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes the adlists, and updates gravity once. When the update
+// fails, the resource is kept with the update pending, so that destroying it
+// again runs the update even though the adlists are gone.
+func (r *AdlistsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state AdlistsResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	addresses := []string{}
+	if diags := state.Addresses.ElementsAs(ctx, &addresses, false); diags.HasError() {
+		resp.Diagnostics.AddError(
+			"Error Deleting Pihole adlists",
+			"Could not read the addresses of the adlists to remove.",
+		)
+		return
+	}
+
+	removed, err := r.client.DeleteAdlists(ctx, state.Type.ValueString(), addresses)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting Pihole adlists",
+			"Could not remove the adlists, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	state.GravityPending = types.BoolValue(state.UpdateGravity.ValueBool() && (len(removed) > 0 || state.GravityPending.ValueBool()))
+	if err := r.updateGravity(ctx, &state); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting Pihole adlists",
+			"The adlists are removed, but gravity could not be updated: "+err.Error()+". Destroying the resource again updates gravity.",
+		)
+		diags = resp.State.Set(ctx, &state)
+		resp.Diagnostics.Append(diags...)
+	}
+}
+
+// ImportState adopts the current adlists of the type of the import ID, block
+// or allow, as urls.
+func (r *AdlistsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if req.ID != "block" && req.ID != "allow" {
+		resp.Diagnostics.AddError(
+			"Error Importing Pihole adlists",
+			fmt.Sprintf("%q is not an adlist type, block or allow.", req.ID),
+		)
+		return
+	}
+
+	current, err := r.client.GetAdlists(ctx, req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Importing Pihole adlists",
+			"Could not read the adlists: "+err.Error(),
+		)
+		return
+	}
+
+	state := AdlistsResourceModel{
+		LastUpdated:    types.StringNull(),
+		SourceFile:     types.StringNull(),
+		Type:           types.StringValue(req.ID),
+		Comment:        types.StringNull(),
+		UpdateGravity:  types.BoolValue(true),
+		GravityPending: types.BoolValue(false),
+	}
+	resp.Diagnostics.Append(state.setChanges(ctx, diffAdlists(current, current))...)
+	state.URLs = state.Addresses
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
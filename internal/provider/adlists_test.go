@@ -0,0 +1,250 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestParseAdlistSource(t *testing.T) {
+	source := strings.Join([]string{
+		"# Blocklists of the household",
+		"https://example.com/hosts",
+		"",
+		"  https://example.org/ads.txt   # ads only",
+		"https://example.net/list.txt#fragment",
+		"file:///etc/pihole/local.list",
+	}, "\n")
+
+	addresses, err := parseAdlistSource(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"https://example.com/hosts",
+		"https://example.org/ads.txt",
+		"https://example.net/list.txt#fragment",
+		"file:///etc/pihole/local.list",
+	}
+	if !reflect.DeepEqual(addresses, expected) {
+		t.Fatalf("expected %v, got %v", expected, addresses)
+	}
+
+	if _, err := parseAdlistSource("https://example.com/hosts\nexample.org/hosts\n"); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected an error on line 2, got %v", err)
+	}
+}
+
+func TestDiffAdlists(t *testing.T) {
+	changes := diffAdlists(
+		[]string{"https://a.example/hosts", "https://b.example/hosts"},
+		[]string{"https://c.example/hosts", "https://a.example/hosts", "https://c.example/hosts"},
+	)
+	expected := adlistChanges{
+		Added:     []string{"https://c.example/hosts"},
+		Removed:   []string{"https://b.example/hosts"},
+		Unchanged: []string{"https://a.example/hosts"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, changes)
+	}
+}
+
+func TestClientSetAdlists(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	fakeGravity(fake)
+	client := fake.client()
+
+	addresses := []string{"https://example.com/hosts", "https://a.example/hosts", "https://b.example/hosts"}
+	changes, err := client.SetAdlists(ctx, "block", addresses, "managed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := adlistChanges{
+		Added:     []string{"https://a.example/hosts", "https://b.example/hosts"},
+		Removed:   []string{"https://example.org/hosts"},
+		Unchanged: []string{"https://example.com/hosts"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, changes)
+	}
+	if adds, deletes := fake.count("lists/add"), fake.count("lists/delete"); adds != 1 || deletes != 1 {
+		t.Fatalf("expected a single batch of each, got %d adds and %d deletes", adds, deletes)
+	}
+
+	current, err := client.GetAdlists(ctx, "block")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"https://a.example/hosts", "https://b.example/hosts", "https://example.com/hosts"}; !reflect.DeepEqual(current, expected) {
+		t.Fatalf("expected %v, got %v", expected, current)
+	}
+
+	removed, err := client.DeleteAdlists(ctx, "block", []string{"https://a.example/hosts", "https://gone.example/hosts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(removed, []string{"https://a.example/hosts"}) {
+		t.Fatalf("expected only the existing adlist to be removed, got %v", removed)
+	}
+
+	if _, err := newFakePihole(t).client().GetAdlists(ctx, "block"); err == nil {
+		t.Fatal("expected an error on Pihole v5")
+	}
+}
+
+func TestAdlistsResourceApply(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	fakeGravity(fake)
+	resource := &AdlistsResource{client: fake.client()}
+
+	plan := AdlistsResourceModel{
+		Type:          types.StringValue("block"),
+		Comment:       types.StringNull(),
+		UpdateGravity: types.BoolValue(true),
+	}
+	diags := plan.setChanges(ctx, diffAdlists([]string{"https://example.com/hosts", "https://example.org/hosts"}, []string{"https://example.com/hosts", "https://a.example/hosts"}))
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	if err := resource.apply(ctx, &plan, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := resource.updateGravity(ctx, &plan); err != nil {
+		t.Fatal(err)
+	}
+	if runs := fake.count("gravity/run"); runs != 1 || plan.GravityPending.ValueBool() {
+		t.Fatalf("expected gravity to be updated once, got %d runs, pending %v", runs, plan.GravityPending)
+	}
+
+	// Nothing to change, nothing to update
+	diags = plan.setChanges(ctx, diffAdlists([]string{"https://a.example/hosts", "https://example.com/hosts"}, []string{"https://example.com/hosts", "https://a.example/hosts"}))
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if err := resource.apply(ctx, &plan, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := resource.updateGravity(ctx, &plan); err != nil {
+		t.Fatal(err)
+	}
+	if runs := fake.count("gravity/run"); runs != 1 {
+		t.Fatalf("expected gravity not to be updated again, got %d runs", runs)
+	}
+}
+
+func TestAdlistsResourceGravityTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { gravityTimeout = timeout }(gravityTimeout)
+	gravityTimeout = 200 * time.Millisecond
+
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	fakeGravity(fake)
+	var delay atomic.Int64
+	fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == "/api/action/gravity" {
+			time.Sleep(time.Duration(delay.Load()))
+		}
+		return false
+	}
+
+	// Gravity outlasts the request timeout
+	httpClient, _ := newHTTPClient(transportConfig{Timeout: 50 * time.Millisecond})
+	client := newPiholeClient(newV6API(fake.APIURL(), fakePiholePassword, newRetryClient(httpClient, defaultMaxRetries)))
+	delay.Store(int64(100 * time.Millisecond))
+	if err := client.UpdateGravity(ctx); err != nil {
+		t.Fatalf("expected gravity to outlast the request timeout, got %v", err)
+	}
+
+	// A timed out update stays pending
+	resource := &AdlistsResource{client: client}
+	plan := AdlistsResourceModel{
+		Type:          types.StringValue("block"),
+		Comment:       types.StringNull(),
+		UpdateGravity: types.BoolValue(true),
+	}
+	diags := plan.setChanges(ctx, diffAdlists([]string{"https://example.com/hosts", "https://example.org/hosts"}, []string{"https://example.com/hosts"}))
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if err := resource.apply(ctx, &plan, false); err != nil {
+		t.Fatal(err)
+	}
+	delay.Store(int64(time.Second))
+	err := resource.updateGravity(ctx, &plan)
+	if err == nil || !strings.Contains(err.Error(), "did not complete within 200ms") {
+		t.Fatalf("expected gravity to time out, got %v", err)
+	}
+	if !plan.GravityPending.ValueBool() {
+		t.Fatal("expected the gravity update to stay pending")
+	}
+
+	// The next apply updates gravity again, without adlist changes
+	diags = plan.setChanges(ctx, diffAdlists([]string{"https://example.com/hosts"}, []string{"https://example.com/hosts"}))
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if err := resource.apply(ctx, &plan, plan.GravityPending.ValueBool()); err != nil {
+		t.Fatal(err)
+	}
+	delay.Store(0)
+	if err := resource.updateGravity(ctx, &plan); err != nil {
+		t.Fatal(err)
+	}
+	if runs := fake.count("gravity/run"); runs != 3 || plan.GravityPending.ValueBool() {
+		t.Fatalf("expected gravity to be updated again, got %d runs, pending %v", runs, plan.GravityPending)
+	}
+}
+
+func TestClientUpdateGravityAlone(t *testing.T) {
+	fastRetries(t)
+
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	fakeGravity(fake)
+	client := fake.client()
+
+	// Writes go on while gravity updates
+	started, release := make(chan struct{}), make(chan struct{})
+	fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == "/api/action/gravity" {
+			close(started)
+			<-release
+		}
+		return false
+	}
+	done := make(chan error)
+	go func() { done <- client.UpdateGravity(ctx) }()
+	<-started
+	if _, err := client.SetAdlists(ctx, "block", []string{"https://example.com/hosts"}, "managed"); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed update is left to the next apply rather than retried
+	fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == "/api/action/gravity" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return true
+		}
+		return false
+	}
+	if err := client.UpdateGravity(ctx); err == nil {
+		t.Fatal("expected the gravity update to fail")
+	}
+	if runs := fake.count("gravity/run"); runs != 2 {
+		t.Fatalf("expected the failed update not to be retried, got %d runs", runs)
+	}
+}
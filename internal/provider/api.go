@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	pihole "github.com/NicoFgrx/pihole-api-go/api"
+)
+
+// piholeAPI is implemented by each supported version of the Pihole API.
+type piholeAPI interface {
+	// Version returns the major version of Pihole serving the API.
+	Version() int
+
+	// Check verifies that Pihole is reachable, supported and accepts the
+	// credentials. It returns errUnreachable, errWrongAPIPath,
+	// errBadCredentials or errUnsupportedVersion for the failures users can fix.
+	Check(ctx context.Context) error
+
+	GetAllCustomDNS(ctx context.Context) ([]pihole.DNSRecordParams, error)
+	AddCustomDNS(ctx context.Context, params *pihole.DNSRecordParams) error
+	DeleteCustomDNS(ctx context.Context, params *pihole.DNSRecordParams) error
+
+	GetAllCustomCNAME(ctx context.Context) ([]pihole.CNAMERecordParams, error)
+	AddCustomCNAME(ctx context.Context, params *pihole.CNAMERecordParams) error
+	DeleteCustomCNAME(ctx context.Context, params *pihole.CNAMERecordParams) error
+
+	// GetQueries sends the queries of the query log selected by the filter, or
+	// a superset of them, to page one batch at a time while page returns true.
+	GetQueries(ctx context.Context, filter queryFilter, page func([]piholeQuery) bool) error
+
+	// GetTopItems returns up to count domains, blocked domains, clients or
+	// upstream servers with the most queries, in no particular order.
+	GetTopItems(ctx context.Context, kind string, count int) ([]topItem, error)
+}
+
+// piholeCredentials are the secrets available to authenticate with Pihole.
+type piholeCredentials struct {
+	// Token is the v5 API token, or a v6 application password.
+	Token string
+	// Password is the web interface password.
+	Password string
+}
+
+// newPiholeAPI detects the version of the Pihole API at apiURL and returns a
+// client for it, authenticated with the matching credential.
+func newPiholeAPI(ctx context.Context, apiURL string, credentials piholeCredentials, httpClient *http.Client) (piholeAPI, error) {
+	version, endpoint, err := detectAPIVersion(ctx, apiURL, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	if version == 5 {
+		token := credentials.Token
+		if token == "" {
+			token = v5Token(credentials.Password)
+		}
+		return newV5API(endpoint, token, httpClient), nil
+	}
+
+	password := credentials.Token
+	if password == "" {
+		password = credentials.Password
+	}
+	return newV6API(endpoint, password, httpClient), nil
+}
+
+// detectAPIVersion tells the Pihole v5 API, served by api.php, from the v6
+// REST API, and returns the endpoint to use for it. apiURL may be the full
+// API URL, or the address of Pihole with or without scheme, port and /admin.
+func detectAPIVersion(ctx context.Context, apiURL string, httpClient *http.Client) (int, string, error) {
+	u, err := normaliseURL(apiURL)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if strings.HasSuffix(u.Path, "api.php") {
+		return 5, u.String(), nil
+	}
+
+	// Pihole may be served under a prefix by a reverse proxy. The suffixes
+	// are trimmed from the last one, as in /admin/api.
+	base := *u
+	for _, suffix := range []string{"/api", "/admin"} {
+		base.Path = strings.TrimSuffix(base.Path, suffix)
+	}
+
+	// The v6 API describes the session of unauthenticated calls
+	endpoint := base.String() + "/api"
+	var auth v6AuthResponse
+	found, err := probeJSON(ctx, httpClient, endpoint+"/auth", &auth)
+	if err != nil {
+		return 0, "", err
+	}
+	if found && auth.Session != nil {
+		return 6, endpoint, nil
+	}
+
+	// The v5 API tells its versions to unauthenticated calls
+	v5Endpoint := base.String() + "/admin/api.php"
+	var versions map[string]interface{}
+	found, err = probeJSON(ctx, httpClient, v5Endpoint+"?versions", &versions)
+	if err != nil {
+		return 0, "", err
+	}
+	if found && versions != nil {
+		return 5, v5Endpoint, nil
+	}
+
+	return 0, "", fmt.Errorf("%w: neither %s nor %s answered", errWrongAPIPath, endpoint, v5Endpoint)
+}
+
+// normaliseURL parses the URL of Pihole given by users, defaulting to HTTP
+// when the scheme is missing.
+func normaliseURL(apiURL string) (*url.URL, error) {
+	apiURL = strings.TrimSpace(apiURL)
+	if !strings.Contains(apiURL, "://") {
+		apiURL = "http://" + apiURL
+	}
+
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: unsupported scheme %q, use http or https", errWrongAPIPath, u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("%w: %s has no host", errWrongAPIPath, apiURL)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	return u, nil
+}
+
+// probeJSON sends an unauthenticated request and decodes the answer into out.
+// It reports whether the server answered JSON, and errors only when the
+// server cannot be reached.
+func probeJSON(ctx context.Context, httpClient *http.Client, probeURL string, out interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL, nil)
+	if err != nil {
+		return false, err
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s", errUnreachable, redactError(err))
+	}
+	defer res.Body.Close()
+
+	return json.NewDecoder(res.Body).Decode(out) == nil, nil
+}
@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	pihole "github.com/NicoFgrx/pihole-api-go/api"
+)
+
+func TestNewPiholeAPI(t *testing.T) {
+	ctx := context.Background()
+
+	fakeV5 := newFakePihole(t)
+	fakeV6 := newFakePiholeV6(t)
+
+	tests := map[string]struct {
+		url         string
+		credentials piholeCredentials
+		version     int
+		authorized  bool
+	}{
+		"v5 token":          {url: fakeV5.APIURL(), credentials: piholeCredentials{Token: fakePiholeToken}, version: 5, authorized: true},
+		"v5 password":       {url: fakeV5.APIURL(), credentials: piholeCredentials{Password: fakePiholePassword}, version: 5, authorized: true},
+		"v6 password":       {url: fakeV6.URL, credentials: piholeCredentials{Password: fakePiholePassword}, version: 6, authorized: true},
+		"v6 app password":   {url: fakeV6.URL + "/api", credentials: piholeCredentials{Token: fakePiholeAppPassword}, version: 6, authorized: true},
+		"v6 token first":    {url: fakeV6.URL, credentials: piholeCredentials{Token: fakePiholeAppPassword, Password: "wrong"}, version: 6, authorized: true},
+		"v6 wrong password": {url: fakeV6.URL, credentials: piholeCredentials{Password: "wrong"}, version: 6, authorized: false},
+	}
+
+	for name, test := range tests {
+		api, err := newPiholeAPI(ctx, test.url, test.credentials, http.DefaultClient)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if api.Version() != test.version {
+			t.Errorf("%s: expected version %d, got %d", name, test.version, api.Version())
+		}
+
+		err = api.AddCustomDNS(ctx, &pihole.DNSRecordParams{Domain: strings.ReplaceAll(name, " ", "-") + ".lan", IP: "1.2.3.4"})
+		if (err == nil) != test.authorized {
+			t.Errorf("%s: expected authorized=%t, got error %v", name, test.authorized, err)
+		}
+	}
+
+	// The v5 API ignores unauthenticated writes
+	if len(fakeV5.dns) != 2 || len(fakeV6.dns) != 3 {
+		t.Errorf("expected every authorized write to be applied, got v5 %v and v6 %v", fakeV5.dns, fakeV6.dns)
+	}
+}
+
+func TestNewPiholeAPIUnknown(t *testing.T) {
+	fake := newFakePihole(t)
+
+	if _, err := newPiholeAPI(context.Background(), fake.URL+"/other", piholeCredentials{Password: fakePiholePassword}, http.DefaultClient); err == nil {
+		t.Fatal("expected an error for an URL serving neither API")
+	}
+}
+
+func TestV6APIRecords(t *testing.T) {
+	ctx := context.Background()
+	client := newFakePiholeV6(t).client()
+
+	dnsrecord := &pihole.DNSRecordParams{Domain: "host.example.com", IP: "fd00::1"}
+	cnamerecord := &pihole.CNAMERecordParams{Domain: "www.example.com", Target: "host.example.com"}
+
+	if err := client.AddCustomDNS(ctx, dnsrecord); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AddCustomCNAME(ctx, cnamerecord); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AddCustomDNS(ctx, dnsrecord); err == nil {
+		t.Fatal("expected an error adding a duplicate record")
+	}
+
+	gotDNS, err := client.GetCustomDNS(ctx, "host.example.com")
+	if err != nil || !reflect.DeepEqual(gotDNS, *dnsrecord) {
+		t.Fatalf("expected %+v, got %+v (%v)", *dnsrecord, gotDNS, err)
+	}
+	gotCNAME, err := client.GetCustomCNAME(ctx, "www.example.com")
+	if err != nil || !reflect.DeepEqual(gotCNAME, *cnamerecord) {
+		t.Fatalf("expected %+v, got %+v (%v)", *cnamerecord, gotCNAME, err)
+	}
+
+	if err := client.DeleteCustomCNAME(ctx, cnamerecord); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteCustomDNS(ctx, dnsrecord); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetCustomDNS(ctx, "host.example.com"); err == nil {
+		t.Fatal("expected the record to be deleted")
+	}
+}
+
+func TestV6APIParsesConfig(t *testing.T) {
+	ctx := context.Background()
+
+	fake := newFakePiholeV6(t)
+	fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != http.MethodGet || r.URL.Path == "/api/auth" {
+			return false
+		}
+		_, _ = w.Write([]byte(`{"config":{"dns":{
+			"hosts":["10.0.0.1 a.lan b.lan","malformed"],
+			"cnameRecords":["c.lan,d.lan,a.lan","e.lan,a.lan,300"]
+		}}}`))
+		return true
+	}
+	client := fake.client()
+
+	dnsrecords, err := client.GetAllCustomDNS(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDNS := []pihole.DNSRecordParams{{Domain: "a.lan", IP: "10.0.0.1"}, {Domain: "b.lan", IP: "10.0.0.1"}}
+	if !reflect.DeepEqual(dnsrecords, wantDNS) {
+		t.Errorf("expected %+v, got %+v", wantDNS, dnsrecords)
+	}
+
+	cnamerecords, err := client.GetAllCustomCNAME(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCNAME := []pihole.CNAMERecordParams{
+		{Domain: "c.lan", Target: "a.lan"},
+		{Domain: "d.lan", Target: "a.lan"},
+		{Domain: "e.lan", Target: "a.lan"},
+	}
+	if !reflect.DeepEqual(cnamerecords, wantCNAME) {
+		t.Errorf("expected %+v, got %+v", wantCNAME, cnamerecords)
+	}
+}
+
+func TestDetectAPIVersionNormalisesURL(t *testing.T) {
+	ctx := context.Background()
+
+	fakeV5 := newFakePihole(t)
+	fakeV6 := newFakePiholeV6(t)
+	hostV5 := strings.TrimPrefix(fakeV5.URL, "http://")
+	hostV6 := strings.TrimPrefix(fakeV6.URL, "http://")
+
+	tests := map[string]struct {
+		url      string
+		version  int
+		endpoint string
+	}{
+		"v5 bare host":    {url: hostV5, version: 5, endpoint: fakeV5.URL + "/admin/api.php"},
+		"v5 base URL":     {url: fakeV5.URL + "/", version: 5, endpoint: fakeV5.URL + "/admin/api.php"},
+		"v5 admin":        {url: fakeV5.URL + "/admin/", version: 5, endpoint: fakeV5.URL + "/admin/api.php"},
+		"v5 api.php":      {url: fakeV5.URL + "/admin/api.php", version: 5, endpoint: fakeV5.URL + "/admin/api.php"},
+		"v5 api.php auth": {url: fakeV5.URL + "/admin/api.php?auth=x", version: 5, endpoint: fakeV5.URL + "/admin/api.php"},
+		"v6 bare host":    {url: hostV6, version: 6, endpoint: fakeV6.URL + "/api"},
+		"v6 base URL":     {url: fakeV6.URL, version: 6, endpoint: fakeV6.URL + "/api"},
+		"v6 admin":        {url: fakeV6.URL + "/admin", version: 6, endpoint: fakeV6.URL + "/api"},
+		"v6 api":          {url: fakeV6.URL + "/api/", version: 6, endpoint: fakeV6.URL + "/api"},
+		"v6 admin api":    {url: fakeV6.URL + "/admin/api", version: 6, endpoint: fakeV6.URL + "/api"},
+		"v5 admin api":    {url: fakeV5.URL + "/admin/api", version: 5, endpoint: fakeV5.URL + "/admin/api.php"},
+		"v5 api":          {url: fakeV5.URL + "/api", version: 5, endpoint: fakeV5.URL + "/admin/api.php"},
+	}
+
+	for name, test := range tests {
+		version, endpoint, err := detectAPIVersion(ctx, test.url, http.DefaultClient)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		if version != test.version || endpoint != test.endpoint {
+			t.Errorf("%s: expected v%d at %s, got v%d at %s", name, test.version, test.endpoint, version, endpoint)
+		}
+	}
+
+	if _, _, err := detectAPIVersion(ctx, "ftp://pi.hole", http.DefaultClient); !errors.Is(err, errWrongAPIPath) {
+		t.Errorf("expected an error for an unsupported scheme, got: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+
+	pihole "github.com/NicoFgrx/pihole-api-go/api"
+)
+
+// v5API is the Pihole v5 API served by api.php, authenticated with a token.
+type v5API struct {
+	base *pihole.Client
+}
+
+// newV5API returns a client for the Pihole v5 API at url, sending its
+// requests through httpClient.
+func newV5API(url string, token string, httpClient *http.Client) *v5API {
+	base := pihole.NewClient(url, token)
+	base.HTTPClient = httpClient
+
+	return &v5API{base: base}
+}
+
+func (a *v5API) Version() int {
+	return 5
+}
+
+// withContext returns the Pihole API client sending its requests with the
+// given context, its logs masking the API token.
+func (a *v5API) withContext(ctx context.Context) *pihole.Client {
+	next := a.base.HTTPClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	api := *a.base
+	api.HTTPClient = &http.Client{
+		Transport: &contextTransport{
+			ctx:  maskSecrets(ctx, a.base.APIKey),
+			next: next,
+		},
+		Timeout: a.base.HTTPClient.Timeout,
+	}
+
+	return &api
+}
+
+func (a *v5API) GetAllCustomDNS(ctx context.Context) ([]pihole.DNSRecordParams, error) {
+	dnsrecords, err := a.withContext(ctx).GetAllCustomDNS()
+	return dnsrecords, redactError(err)
+}
+
+func (a *v5API) AddCustomDNS(ctx context.Context, params *pihole.DNSRecordParams) error {
+	return redactError(a.withContext(ctx).AddCustomDNS(params))
+}
+
+func (a *v5API) DeleteCustomDNS(ctx context.Context, params *pihole.DNSRecordParams) error {
+	return redactError(a.withContext(ctx).DeleteCustomDNS(params))
+}
+
+func (a *v5API) GetAllCustomCNAME(ctx context.Context) ([]pihole.CNAMERecordParams, error) {
+	cnamerecords, err := a.withContext(ctx).GetAllCustomCNAME()
+	return cnamerecords, redactError(err)
+}
+
+func (a *v5API) AddCustomCNAME(ctx context.Context, params *pihole.CNAMERecordParams) error {
+	return redactError(a.withContext(ctx).AddCustomCNAME(params))
+}
+
+func (a *v5API) DeleteCustomCNAME(ctx context.Context, params *pihole.CNAMERecordParams) error {
+	return redactError(a.withContext(ctx).DeleteCustomCNAME(params))
+}
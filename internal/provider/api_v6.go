@@ -0,0 +1,355 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	pihole "github.com/NicoFgrx/pihole-api-go/api"
+)
+
+// v6API is the Pihole v6 REST API, authenticated with a session opened with
+// the web password or an application password.
+type v6API struct {
+	endpoint   string
+	password   string
+	httpClient *http.Client
+
+	// mu guards the session shared by the resources applied in parallel.
+	mu            sync.Mutex
+	authenticated bool
+	sid           string
+}
+
+// v6AuthResponse is the answer of the /auth endpoint.
+type v6AuthResponse struct {
+	Session *v6Session `json:"session"`
+}
+
+// v6Session describes an API session. Pihole returns a valid session without
+// SID when no password is set.
+type v6Session struct {
+	Valid    bool   `json:"valid"`
+	SID      string `json:"sid"`
+	CSRF     string `json:"csrf"`
+	Validity int    `json:"validity"`
+	Message  string `json:"message"`
+}
+
+// v6Error is an error answered by the v6 API.
+type v6Error struct {
+	Status  int
+	Key     string `json:"key"`
+	Message string `json:"message"`
+	Hint    string `json:"hint"`
+}
+
+func (e *v6Error) Error() string {
+	if e.Hint != "" {
+		return fmt.Sprintf("%s (%s)", e.Message, e.Hint)
+	}
+
+	return e.Message
+}
+
+// rawBody is a request body sent as is rather than encoded to JSON.
+type rawBody struct {
+	contentType string
+	data        []byte
+}
+
+// newV6API returns a client for the Pihole v6 API at endpoint, sending its
+// requests through httpClient.
+func newV6API(endpoint string, password string, httpClient *http.Client) *v6API {
+	return &v6API{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		password:   password,
+		httpClient: httpClient,
+	}
+}
+
+func (a *v6API) Version() int {
+	return 6
+}
+
+// session returns the SID of the current session, logging in if needed.
+func (a *v6API) session(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.authenticated {
+		return a.sid, nil
+	}
+
+	var auth v6AuthResponse
+	err := a.send(ctx, http.MethodPost, "/auth", "", map[string]string{"password": a.password}, &auth)
+	if err != nil {
+		return "", err
+	}
+	if auth.Session == nil || !auth.Session.Valid {
+		return "", &v6Error{Status: http.StatusUnauthorized, Key: "unauthorized", Message: "Pihole rejected the password"}
+	}
+
+	a.authenticated = true
+	a.sid = auth.Session.SID
+
+	return a.sid, nil
+}
+
+// expireSession forgets the session, unless it was already replaced.
+func (a *v6API) expireSession(sid string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.sid == sid {
+		a.authenticated = false
+		a.sid = ""
+	}
+}
+
+// logout logs the session of the API out, if any. The next call logs in
+// again.
+func (a *v6API) logout(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.authenticated {
+		return nil
+	}
+	sid := a.sid
+	a.authenticated, a.sid = false, ""
+
+	// Sessions without SID, opened when Pihole has no password, have nothing
+	// to close
+	if sid == "" {
+		return nil
+	}
+
+	return a.send(ctx, http.MethodDelete, "/auth", sid, nil, nil)
+}
+
+// do sends an authenticated request, logging in again once if the session
+// expired, and decodes the answer into out.
+func (a *v6API) do(ctx context.Context, method string, path string, body interface{}, out interface{}) error {
+	sid, err := a.session(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = a.send(ctx, method, path, sid, body, out)
+	var apiErr *v6Error
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusUnauthorized {
+		a.expireSession(sid)
+		if sid, err = a.session(ctx); err != nil {
+			return err
+		}
+		err = a.send(ctx, method, path, sid, body, out)
+	}
+
+	return err
+}
+
+// send sends a request with the given session and decodes the answer into out.
+// A rawBody is sent as is, and a *[]byte out receives the raw answer.
+func (a *v6API) send(ctx context.Context, method string, path string, sid string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	contentType := "application/json"
+	switch body := body.(type) {
+	case nil:
+	case rawBody:
+		reader = bytes.NewReader(body.data)
+		contentType = body.contentType
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	ctx = maskSecrets(ctx, a.password, sid)
+	req, err := http.NewRequestWithContext(ctx, method, a.endpoint+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if sid != "" {
+		req.Header.Set("X-FTL-SID", sid)
+	}
+
+	res, err := a.httpClient.Do(req)
+	if err != nil {
+		return redactError(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		var answer struct {
+			Error *v6Error `json:"error"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&answer); err != nil || answer.Error == nil {
+			return &v6Error{Status: res.StatusCode, Message: "Pihole answered " + res.Status}
+		}
+		answer.Error.Status = res.StatusCode
+		return answer.Error
+	}
+
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if raw, ok := out.(*[]byte); ok {
+		*raw, err = io.ReadAll(res.Body)
+		return err
+	}
+
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// configPath returns the API path of a dotted configuration key such as
+// dns.hosts.
+func configPath(key string) string {
+	return "/config/" + strings.ReplaceAll(key, ".", "/")
+}
+
+// getConfig decodes the value of a dotted configuration key into out.
+func (a *v6API) getConfig(ctx context.Context, key string, out interface{}) error {
+	var answer struct {
+		Config map[string]json.RawMessage `json:"config"`
+	}
+	if err := a.do(ctx, http.MethodGet, configPath(key), nil, &answer); err != nil {
+		return err
+	}
+
+	// The answer nests the value under each part of the key
+	parts := strings.Split(key, ".")
+	value, ok := answer.Config[parts[0]]
+	for _, part := range parts[1:] {
+		if !ok {
+			break
+		}
+		var level map[string]json.RawMessage
+		if err := json.Unmarshal(value, &level); err != nil {
+			return fmt.Errorf("unexpected value for configuration %s: %w", key, err)
+		}
+		value, ok = level[part]
+	}
+	if !ok {
+		return fmt.Errorf("configuration %s not found", key)
+	}
+
+	return json.Unmarshal(value, out)
+}
+
+// setConfig sets the values of dotted configuration keys, leaving the other
+// keys unchanged.
+func (a *v6API) setConfig(ctx context.Context, values map[string]interface{}) error {
+	config := map[string]interface{}{}
+	for key, value := range values {
+		// The body nests the value under each part of the key
+		parts := strings.Split(key, ".")
+		level := config
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := level[part].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				level[part] = next
+			}
+			level = next
+		}
+		level[parts[len(parts)-1]] = value
+	}
+
+	return a.do(ctx, http.MethodPatch, "/config", map[string]interface{}{"config": config}, nil)
+}
+
+// addConfigItem adds an item to an array configuration key.
+func (a *v6API) addConfigItem(ctx context.Context, key string, item string) error {
+	return a.do(ctx, http.MethodPut, configPath(key)+"/"+url.PathEscape(item), nil, nil)
+}
+
+// deleteConfigItem removes an item from an array configuration key.
+func (a *v6API) deleteConfigItem(ctx context.Context, key string, item string) error {
+	return a.do(ctx, http.MethodDelete, configPath(key)+"/"+url.PathEscape(item), nil, nil)
+}
+
+func (a *v6API) GetAllCustomDNS(ctx context.Context) ([]pihole.DNSRecordParams, error) {
+	var hosts []string
+	if err := a.getConfig(ctx, "dns.hosts", &hosts); err != nil {
+		return nil, err
+	}
+
+	var customdns_lst []pihole.DNSRecordParams
+
+	// Each entry is an IP followed by one or more hostnames
+	for _, host := range hosts {
+		fields := strings.Fields(host)
+		if len(fields) < 2 {
+			continue
+		}
+		for _, domain := range fields[1:] {
+			customdns_lst = append(customdns_lst, pihole.DNSRecordParams{
+				Domain: domain,
+				IP:     fields[0],
+			})
+		}
+	}
+
+	return customdns_lst, nil
+}
+
+func (a *v6API) AddCustomDNS(ctx context.Context, params *pihole.DNSRecordParams) error {
+	return a.addConfigItem(ctx, "dns.hosts", params.IP+" "+params.Domain)
+}
+
+func (a *v6API) DeleteCustomDNS(ctx context.Context, params *pihole.DNSRecordParams) error {
+	return a.deleteConfigItem(ctx, "dns.hosts", params.IP+" "+params.Domain)
+}
+
+func (a *v6API) GetAllCustomCNAME(ctx context.Context) ([]pihole.CNAMERecordParams, error) {
+	var records []string
+	if err := a.getConfig(ctx, "dns.cnameRecords", &records); err != nil {
+		return nil, err
+	}
+
+	var customcname_lst []pihole.CNAMERecordParams
+
+	// Each entry is one or more aliases, the target, then an optional TTL
+	for _, record := range records {
+		fields := strings.Split(record, ",")
+		if _, err := strconv.Atoi(fields[len(fields)-1]); err == nil && len(fields) > 2 {
+			fields = fields[:len(fields)-1]
+		}
+		if len(fields) < 2 {
+			continue
+		}
+
+		target := fields[len(fields)-1]
+		for _, domain := range fields[:len(fields)-1] {
+			customcname_lst = append(customcname_lst, pihole.CNAMERecordParams{
+				Domain: domain,
+				Target: target,
+			})
+		}
+	}
+
+	return customcname_lst, nil
+}
+
+func (a *v6API) AddCustomCNAME(ctx context.Context, params *pihole.CNAMERecordParams) error {
+	return a.addConfigItem(ctx, "dns.cnameRecords", params.Domain+","+params.Target)
+}
+
+func (a *v6API) DeleteCustomCNAME(ctx context.Context, params *pihole.CNAMERecordParams) error {
+	return a.deleteConfigItem(ctx, "dns.cnameRecords", params.Domain+","+params.Target)
+}
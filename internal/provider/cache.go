@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultCacheTTL is how long a list fetched from Pihole answers reads. It
+// covers a refresh, where each resource reads the list its record is in.
+const defaultCacheTTL = 10 * time.Second
+
+// listCache keeps a list fetched from Pihole for a short time, so the
+// resources refreshed in a plan share a single fetch. Concurrent reads of an
+// expired list wait for one fetch rather than sending their own.
+type listCache[T any] struct {
+	// ttl is how long the list is kept, caching being disabled when zero.
+	ttl time.Duration
+
+	mu      sync.Mutex
+	items   []T
+	fetched time.Time
+	valid   bool
+}
+
+// get returns a copy of the cached list, fetching it when it expired. Errors
+// are not cached.
+func (l *listCache[T]) get(fetch func() ([]T, error)) ([]T, error) {
+	if l.ttl <= 0 {
+		return fetch()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.valid || time.Since(l.fetched) >= l.ttl {
+		items, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		l.items, l.fetched, l.valid = items, time.Now(), true
+	}
+
+	return append([]T(nil), l.items...), nil
+}
+
+// invalidate drops the cached list, so the next read fetches it again. A
+// fetch in progress completes first, so its possibly stale list is dropped.
+func (l *listCache[T]) invalidate() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.items, l.valid = nil, false
+}
@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	pihole "github.com/NicoFgrx/pihole-api-go/api"
+)
+
+func TestClientCachesLists(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePihole(t)
+	client := fake.client()
+
+	for i := 0; i < 3; i++ {
+		if err := client.AddCustomDNS(ctx, &pihole.DNSRecordParams{Domain: fmt.Sprintf("host%d.example.com", i), IP: "10.0.0.1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	before := fake.count("customdns/get")
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetCustomDNS(ctx, fmt.Sprintf("host%d.example.com", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := fake.count("customdns/get") - before; got != 1 {
+		t.Fatalf("expected the reads to share one fetch, got %d", got)
+	}
+
+	// Writes drop the cached list
+	if err := client.DeleteCustomDNS(ctx, &pihole.DNSRecordParams{Domain: "host0.example.com", IP: "10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetCustomDNS(ctx, "host0.example.com"); err == nil {
+		t.Fatal("expected the deleted record not to be read from the cache")
+	}
+
+	// The list is fetched again once expired
+	client.dnsCache.ttl = time.Millisecond
+	time.Sleep(2 * time.Millisecond)
+	before = fake.count("customdns/get")
+	if _, err := client.GetAllCustomDNS(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.count("customdns/get") - before; got != 1 {
+		t.Fatalf("expected the expired list to be fetched, got %d fetches", got)
+	}
+}
+
+func TestListCacheDoesNotCacheErrors(t *testing.T) {
+	cache := listCache[string]{ttl: time.Minute}
+	fetches := 0
+
+	fail := func() ([]string, error) {
+		fetches++
+		return nil, errors.New("unreachable")
+	}
+	if _, err := cache.get(fail); err == nil {
+		t.Fatal("expected the fetch error")
+	}
+
+	items, err := cache.get(func() ([]string, error) {
+		fetches++
+		return []string{"a"}, nil
+	})
+	if err != nil || len(items) != 1 || fetches != 2 {
+		t.Fatalf("expected the list to be fetched again after an error, got %v, %v after %d fetches", items, err, fetches)
+	}
+
+	// Callers get a copy of the cached list
+	items[0] = "b"
+	if items, _ := cache.get(fail); items[0] != "a" {
+		t.Fatalf("expected the cached list not to be modified, got %v", items)
+	}
+}
+
+// BenchmarkRefresh reads each of 500 records as a refresh does, and reports
+// the number of list fetches sent to Pihole per refresh.
+func BenchmarkRefresh(b *testing.B) {
+	const records = 500
+
+	for name, ttl := range map[string]time.Duration{"uncached": 0, "cached": defaultCacheTTL} {
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			fake := newFakePihole(b)
+			for i := 0; i < records; i++ {
+				fake.dns = append(fake.dns, []string{fmt.Sprintf("host%d.example.com", i), "10.0.0.1"})
+			}
+
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				// Each plan configures a new provider instance
+				client := fake.client()
+				client.dnsCache.ttl = ttl
+				for i := 0; i < records; i++ {
+					if _, err := client.GetCustomDNS(ctx, fmt.Sprintf("host%d.example.com", i)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+			b.ReportMetric(float64(fake.count("customdns/get"))/float64(b.N), "fetches/refresh")
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	pihole "github.com/NicoFgrx/pihole-api-go/api"
+)
+
+// piholeClient is the provider configured client handed to resources and
+// data sources. It wraps the Pihole API client with the provider-level
+// settings resources need at plan and apply time.
+type piholeClient struct {
+	api piholeAPI
+
+	// strictCnameTargets turns unresolved CNAME target warnings into errors.
+	strictCnameTargets bool
+
+	// maxRetries bounds the retries of a write failing with a transient error.
+	maxRetries int
+
+	// writeMu serialises the writes to this Pihole instance: custom.list and
+	// the FTL configuration are rewritten as a whole on each change, so
+	// concurrent writes lose records. Reads do not take the lock.
+	writeMu sync.Mutex
+
+	// The custom DNS and CNAME lists shared by the reads of a plan, dropped
+	// on each write.
+	dnsCache   listCache[pihole.DNSRecordParams]
+	cnameCache listCache[pihole.CNAMERecordParams]
+
+	// localDomainCache holds the local domain qualifying short host names.
+	localDomainCache listCache[localDomain]
+
+	// dnsmasqCache holds the extra dnsmasq lines, read by the extra records
+	// and the PTR records of the custom DNS records.
+	dnsmasqCache listCache[string]
+}
+
+// newPiholeClient returns a client sending its requests to the given API.
+func newPiholeClient(api piholeAPI) *piholeClient {
+	return &piholeClient{
+		api:        api,
+		maxRetries: defaultMaxRetries,
+		dnsCache:   listCache[pihole.DNSRecordParams]{ttl: defaultCacheTTL},
+		cnameCache: listCache[pihole.CNAMERecordParams]{ttl: defaultCacheTTL},
+
+		localDomainCache: listCache[localDomain]{ttl: defaultCacheTTL},
+		dnsmasqCache:     listCache[string]{ttl: defaultCacheTTL},
+	}
+}
+
+// GetAllCustomDNS lists the custom DNS records, fetched at most once per
+// cache TTL.
+func (c *piholeClient) GetAllCustomDNS(ctx context.Context) ([]pihole.DNSRecordParams, error) {
+	return c.dnsCache.get(func() ([]pihole.DNSRecordParams, error) {
+		return c.api.GetAllCustomDNS(ctx)
+	})
+}
+
+// GetCustomDNS returns the custom DNS record of the given domain, whatever the
+// case of the domain, as the checks of retried writes do.
+func (c *piholeClient) GetCustomDNS(ctx context.Context, domain string) (pihole.DNSRecordParams, error) {
+	dnsrecords, err := c.GetAllCustomDNS(ctx)
+	if err != nil {
+		return pihole.DNSRecordParams{}, err
+	}
+
+	for _, item := range dnsrecords {
+		if strings.EqualFold(item.Domain, domain) {
+			return item, nil
+		}
+	}
+
+	return pihole.DNSRecordParams{}, fmt.Errorf("Record %s not found", domain)
+}
+
+// AddCustomDNS creates a custom DNS record, retrying on transient failures.
+func (c *piholeClient) AddCustomDNS(ctx context.Context, params *pihole.DNSRecordParams) error {
+	return c.write(ctx,
+		func() error { return c.api.AddCustomDNS(ctx, params) },
+		func() (bool, error) { return c.hasCustomDNS(ctx, params) },
+	)
+}
+
+// DeleteCustomDNS deletes a custom DNS record, retrying on transient failures.
+func (c *piholeClient) DeleteCustomDNS(ctx context.Context, params *pihole.DNSRecordParams) error {
+	return c.write(ctx,
+		func() error { return c.api.DeleteCustomDNS(ctx, params) },
+		func() (bool, error) {
+			found, err := c.hasCustomDNS(ctx, params)
+			return !found, err
+		},
+	)
+}
+
+// GetAllCustomCNAME lists the custom CNAME records, fetched at most once per
+// cache TTL.
+func (c *piholeClient) GetAllCustomCNAME(ctx context.Context) ([]pihole.CNAMERecordParams, error) {
+	return c.cnameCache.get(func() ([]pihole.CNAMERecordParams, error) {
+		return c.api.GetAllCustomCNAME(ctx)
+	})
+}
+
+// GetCustomCNAME returns the custom CNAME record of the given domain, whatever
+// the case of the domain, as the checks of retried writes do.
+func (c *piholeClient) GetCustomCNAME(ctx context.Context, domain string) (pihole.CNAMERecordParams, error) {
+	cnamerecords, err := c.GetAllCustomCNAME(ctx)
+	if err != nil {
+		return pihole.CNAMERecordParams{}, err
+	}
+
+	for _, item := range cnamerecords {
+		if strings.EqualFold(item.Domain, domain) {
+			return item, nil
+		}
+	}
+
+	return pihole.CNAMERecordParams{}, fmt.Errorf("CNAME %s not found", domain)
+}
+
+// AddCustomCNAME creates a custom CNAME record, retrying on transient failures.
+func (c *piholeClient) AddCustomCNAME(ctx context.Context, params *pihole.CNAMERecordParams) error {
+	return c.write(ctx,
+		func() error { return c.api.AddCustomCNAME(ctx, params) },
+		func() (bool, error) { return c.hasCustomCNAME(ctx, params) },
+	)
+}
+
+// DeleteCustomCNAME deletes a custom CNAME record, retrying on transient failures.
+func (c *piholeClient) DeleteCustomCNAME(ctx context.Context, params *pihole.CNAMERecordParams) error {
+	return c.write(ctx,
+		func() error { return c.api.DeleteCustomCNAME(ctx, params) },
+		func() (bool, error) {
+			found, err := c.hasCustomCNAME(ctx, params)
+			return !found, err
+		},
+	)
+}
+
+// v6 returns the v6 API, for the features Pihole v5 does not offer.
+func (c *piholeClient) v6(feature string) (*v6API, error) {
+	api, ok := c.api.(*v6API)
+	if !ok {
+		return nil, fmt.Errorf("%s requires Pihole v6, the provider is configured for Pihole v%d", feature, c.api.Version())
+	}
+
+	return api, nil
+}
+
+// write runs a write against Pihole once the previous writes are done, and
+// keeps the lock until the outcome of its retries is known. The cached lists
+// are dropped whatever the outcome, as a failed write may have been applied.
+func (c *piholeClient) write(ctx context.Context, write func() error, applied func() (bool, error)) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	defer c.invalidateCaches()
+
+	return c.retryWrite(ctx, write, applied)
+}
+
+// invalidateCaches drops the cached lists.
+func (c *piholeClient) invalidateCaches() {
+	c.dnsCache.invalidate()
+	c.cnameCache.invalidate()
+	c.localDomainCache.invalidate()
+	c.dnsmasqCache.invalidate()
+}
+
+// hasCustomDNS reports whether the exact custom DNS record exists, bypassing
+// the cache as it checks the outcome of a write.
+func (c *piholeClient) hasCustomDNS(ctx context.Context, params *pihole.DNSRecordParams) (bool, error) {
+	dnsrecords, err := c.api.GetAllCustomDNS(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, item := range dnsrecords {
+		if strings.EqualFold(item.Domain, params.Domain) && item.IP == params.IP {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// hasCustomCNAME reports whether the exact custom CNAME record exists,
+// bypassing the cache as it checks the outcome of a write.
+func (c *piholeClient) hasCustomCNAME(ctx context.Context, params *pihole.CNAMERecordParams) (bool, error) {
+	cnamerecords, err := c.api.GetAllCustomCNAME(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, item := range cnamerecords {
+		if strings.EqualFold(item.Domain, params.Domain) && strings.EqualFold(item.Target, params.Target) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
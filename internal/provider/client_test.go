@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	pihole "github.com/NicoFgrx/pihole-api-go/api"
+)
+
+// addRecordsConcurrently adds n custom DNS records in parallel, as Terraform
+// does with -parallelism=n.
+func addRecordsConcurrently(n int, add func(params *pihole.DNSRecordParams) error) []error {
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = add(&pihole.DNSRecordParams{
+				Domain: fmt.Sprintf("host%d.example.com", i),
+				IP:     fmt.Sprintf("10.0.0.%d", i+1),
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+func TestClientSerialisesWrites(t *testing.T) {
+	const parallelism = 50
+
+	fake := newFakePihole(t)
+	fake.writeDelay = 2 * time.Millisecond
+	client := fake.client()
+
+	errs := addRecordsConcurrently(parallelism, func(params *pihole.DNSRecordParams) error {
+		return client.AddCustomDNS(context.Background(), params)
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := client.GetAllCustomDNS(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != parallelism {
+		t.Fatalf("expected %d records, got %d: writes were lost", parallelism, len(records))
+	}
+}
+
+func TestClientUnserialisedWritesAreLost(t *testing.T) {
+	const parallelism = 2
+
+	// Make sure the fake reproduces the race the write lock prevents: each
+	// add waits for the other to copy the list before writing it back
+	fake := newFakePihole(t)
+	var copied sync.WaitGroup
+	copied.Add(parallelism)
+	fake.copied = func() {
+		copied.Done()
+		copied.Wait()
+	}
+	client := fake.client()
+
+	addRecordsConcurrently(parallelism, func(params *pihole.DNSRecordParams) error {
+		return client.api.AddCustomDNS(context.Background(), params)
+	})
+
+	records, err := client.GetAllCustomDNS(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected concurrent writes bypassing the lock to be lost, got %d records", len(records))
+	}
+}
+
+func TestClientReadsDuringWrite(t *testing.T) {
+	fake := newFakePihole(t)
+	client := fake.client()
+
+	release := make(chan struct{})
+	fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Query().Get("action") == "add" {
+			<-release
+		}
+		return false
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- client.AddCustomDNS(context.Background(), &pihole.DNSRecordParams{Domain: "test.example.com", IP: "1.2.3.4"})
+	}()
+
+	// Wait for the write to hold the lock
+	for fake.count("customdns/add") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := client.GetAllCustomDNS(context.Background()); err != nil {
+		t.Fatalf("expected the read not to wait for the write, got: %s", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	pihole "github.com/NicoFgrx/pihole-api-go/api"
+)
+
+// cnameResolution describes how Pihole would resolve a CNAME target using
+// only its local records.
+type cnameResolution struct {
+	// Chain lists the names followed from the target, the target first.
+	Chain []string
+	// Resolved is true when the chain ends on a custom DNS record.
+	Resolved bool
+	// Loop is true when the chain comes back to a name already visited.
+	Loop bool
+}
+
+// resolveCnameTarget follows the CNAME domain -> target through the given
+// custom DNS and CNAME records, as Pihole would when answering a query.
+// The domain -> target pair takes precedence over any existing CNAME for the
+// same domain, so planned changes are checked rather than the current ones.
+func resolveCnameTarget(dnsrecords []pihole.DNSRecordParams, cnamerecords []pihole.CNAMERecordParams, domain string, target string) cnameResolution {
+	records := make(map[string]bool, len(dnsrecords))
+	for _, item := range dnsrecords {
+		records[strings.ToLower(item.Domain)] = true
+	}
+
+	aliases := make(map[string]string, len(cnamerecords)+1)
+	for _, item := range cnamerecords {
+		aliases[strings.ToLower(item.Domain)] = strings.ToLower(item.Target)
+	}
+	aliases[strings.ToLower(domain)] = strings.ToLower(target)
+
+	var res cnameResolution
+	visited := map[string]bool{strings.ToLower(domain): true}
+
+	for current := strings.ToLower(target); ; {
+		if visited[current] {
+			res.Chain = append(res.Chain, current)
+			res.Loop = true
+			return res
+		}
+		visited[current] = true
+		res.Chain = append(res.Chain, current)
+
+		if records[current] {
+			res.Resolved = true
+			return res
+		}
+
+		next, ok := aliases[current]
+		if !ok {
+			return res
+		}
+		current = next
+	}
+}
+
+// checkCnameTarget fetches the local records from Pihole and resolves the
+// CNAME domain -> target against them.
+func (c *piholeClient) checkCnameTarget(ctx context.Context, domain string, target string) (cnameResolution, error) {
+	dnsrecords, err := c.GetAllCustomDNS(ctx)
+	if err != nil {
+		return cnameResolution{}, err
+	}
+
+	cnamerecords, err := c.GetAllCustomCNAME(ctx)
+	if err != nil {
+		return cnameResolution{}, err
+	}
+
+	return resolveCnameTarget(dnsrecords, cnamerecords, domain, target), nil
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	pihole "github.com/NicoFgrx/pihole-api-go/api"
+)
+
+func TestResolveCnameTarget(t *testing.T) {
+	dnsrecords := []pihole.DNSRecordParams{
+		{Domain: "host.example.com", IP: "1.2.3.4"},
+	}
+	cnamerecords := []pihole.CNAMERecordParams{
+		{Domain: "www.example.com", Target: "host.example.com"},
+		{Domain: "a.example.com", Target: "b.example.com"},
+		{Domain: "b.example.com", Target: "a.example.com"},
+		{Domain: "old.example.com", Target: "missing.example.com"},
+		{Domain: "back.example.com", Target: "alias.example.com"},
+	}
+
+	tests := map[string]struct {
+		domain string
+		target string
+		want   cnameResolution
+	}{
+		"direct record": {
+			domain: "alias.example.com",
+			target: "HOST.example.com",
+			want:   cnameResolution{Chain: []string{"host.example.com"}, Resolved: true},
+		},
+		"chain": {
+			domain: "alias.example.com",
+			target: "www.example.com",
+			want:   cnameResolution{Chain: []string{"www.example.com", "host.example.com"}, Resolved: true},
+		},
+		"missing": {
+			domain: "alias.example.com",
+			target: "missing.example.com",
+			want:   cnameResolution{Chain: []string{"missing.example.com"}},
+		},
+		"existing loop": {
+			domain: "alias.example.com",
+			target: "a.example.com",
+			want:   cnameResolution{Chain: []string{"a.example.com", "b.example.com", "a.example.com"}, Loop: true},
+		},
+		"loop back to alias": {
+			domain: "alias.example.com",
+			target: "back.example.com",
+			want:   cnameResolution{Chain: []string{"back.example.com", "alias.example.com"}, Loop: true},
+		},
+		"planned change takes precedence": {
+			domain: "old.example.com",
+			target: "host.example.com",
+			want:   cnameResolution{Chain: []string{"host.example.com"}, Resolved: true},
+		},
+	}
+
+	for name, test := range tests {
+		got := resolveCnameTarget(dnsrecords, cnamerecords, test.domain, test.target)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected %+v, got %+v", name, test.want, got)
+		}
+	}
+}
@@ -3,15 +3,18 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	pihole "github.com/NicoFgrx/pihole-api-go/api"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -21,6 +24,7 @@ var (
 	_ resource.Resource                = &CnameResource{}
 	_ resource.ResourceWithConfigure   = &CnameResource{}
 	_ resource.ResourceWithImportState = &CnameResource{}
+	_ resource.ResourceWithModifyPlan  = &CnameResource{}
 )
 
 // NewcnamerecordResource is a helper function to simplify the provider implementation.
@@ -30,7 +34,7 @@ func NewCnameResource() resource.Resource {
 
 // cnamerecordResource is the resource implementation.
 type CnameResource struct {
-	client *pihole.Client
+	client *piholeClient
 }
 
 // cnamerecordResourceModel maps the resource schema data.
@@ -43,7 +47,6 @@ type CnameResourceModel struct {
 
 // Metadata returns the resource type name.
 func (r *CnameResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	fmt.Println(req.ProviderTypeName)
 	resp.TypeName = req.ProviderTypeName + "_cname"
 }
 
@@ -62,13 +65,20 @@ func (r *CnameResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Validators: []validator.String{
+					hostnameValidator{},
+				},
 			},
 			"target": schema.StringAttribute{
 				Required:    true,
-				Description: "Local managed DNS record",
+				Description: "Local managed DNS record. Pihole only resolves the CNAME when the target is a custom DNS record or another CNAME.",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Validators: []validator.String{
+					hostnameValidator{},
+					cnameTargetValidator{},
+				},
 			},
 		},
 	}
@@ -80,12 +90,12 @@ func (r *CnameResource) Configure(_ context.Context, req resource.ConfigureReque
 		return
 	}
 
-	client, ok := req.ProviderData.(*pihole.Client)
+	client, ok := req.ProviderData.(*piholeClient)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *pihole.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *piholeClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
@@ -94,6 +104,74 @@ func (r *CnameResource) Configure(_ context.Context, req resource.ConfigureReque
 	r.client = client
 }
 
+// ModifyPlan checks that the planned target resolves to a locally managed record.
+func (r *CnameResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check on destroy or before the provider is configured
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var plan CnameResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.Domain.IsUnknown() || plan.Target.IsUnknown() {
+		return
+	}
+
+	res, err := r.client.checkCnameTarget(ctx, plan.Domain.ValueString(), plan.Target.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Unable to check CNAME target",
+			"Could not list local records to check target "+plan.Target.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+
+	// The target may be created in the same apply, so an unresolved target
+	// is only enforced by Create.
+	addCnameTargetDiagnostics(&resp.Diagnostics, res, plan.Domain.ValueString(), false)
+}
+
+// addCnameTargetDiagnostics reports the problems found while resolving a CNAME
+// target. Loops are always errors, unresolved targets only when strict is set.
+func addCnameTargetDiagnostics(diags *diag.Diagnostics, res cnameResolution, domain string, strict bool) {
+	target := res.Chain[0]
+
+	if res.Loop {
+		diags.AddAttributeError(
+			path.Root("target"),
+			"CNAME loop detected",
+			"The CNAME "+domain+" resolves through "+strings.Join(res.Chain, " -> ")+", which loops back on itself.",
+		)
+		return
+	}
+
+	if !res.Resolved {
+		summary := "CNAME target is not a local record"
+		detail := "The target " + target + " does not resolve to a custom DNS record managed by Pihole. " +
+			"Pihole only resolves a CNAME whose target is known locally."
+		if strict {
+			diags.AddAttributeError(path.Root("target"), summary, detail+" Disable strict_cname_targets in the provider to allow it.")
+		} else {
+			diags.AddAttributeWarning(path.Root("target"), summary, detail+" This is expected if the record is created in the same apply.")
+		}
+		return
+	}
+
+	if len(res.Chain) > 1 {
+		diags.AddAttributeWarning(
+			path.Root("target"),
+			"CNAME chain detected",
+			"The CNAME "+domain+" resolves through "+strings.Join(res.Chain, " -> ")+". "+
+				"Consider targeting "+res.Chain[len(res.Chain)-1]+" directly.",
+		)
+	}
+}
+
 // Create a new resource.
 func (r *CnameResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	// Retrieve values from plan
@@ -110,18 +188,32 @@ func (r *CnameResource) Create(ctx context.Context, req resource.CreateRequest,
 		Target: plan.Target.ValueString(),
 	}
 
-	ctx = tflog.SetField(ctx, "api", r.client.APIKey)
-	ctx = tflog.SetField(ctx, "url", r.client.BaseURL)
 	ctx = tflog.SetField(ctx, "domain", data.Domain)
 	ctx = tflog.SetField(ctx, "target", data.Target)
 
+	// Check the target now that its dependencies have been created
+	res, err := r.client.checkCnameTarget(ctx, data.Domain, data.Target)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error checking cname target",
+			"Could not list local records to check target "+data.Target+": "+err.Error(),
+		)
+		return
+	}
+
+	addCnameTargetDiagnostics(&resp.Diagnostics, res, data.Domain, r.client.strictCnameTargets)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Create new cname record
-	err := r.client.AddCustomCNAME(&data)
+	err = r.client.AddCustomCNAME(ctx, &data)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error creating customcname",
 			"Could not create customcname, unexpected error: "+err.Error(),
 		)
+		return
 	}
 
 	// Map response body to schema and populate Computed attribute values
@@ -148,7 +240,7 @@ func (r *CnameResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	}
 
 	// Get refresh cname value
-	cnamerecord, err := r.client.GetCustomCNAME(state.Domain.ValueString())
+	cnamerecord, err := r.client.GetCustomCNAME(ctx, state.Domain.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Pihole cnameRecord",
@@ -191,7 +283,7 @@ func (r *CnameResource) Delete(ctx context.Context, req resource.DeleteRequest,
 	}
 
 	// Delete existing record
-	err := r.client.DeleteCustomCNAME(&to_delete)
+	err := r.client.DeleteCustomCNAME(ctx, &to_delete)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Deleting cname Record ",
@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// configKeyRegexp matches the dotted keys of the FTL configuration, such as
+// webserver.session.timeout.
+var configKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+
+// GetConfig decodes the value of a dotted FTL configuration key into out.
+// feature names what needs the configuration in errors on Pihole v5.
+func (c *piholeClient) GetConfig(ctx context.Context, feature string, key string, out interface{}) error {
+	api, err := c.v6(feature)
+	if err != nil {
+		return err
+	}
+
+	return api.getConfig(ctx, key, out)
+}
+
+// SetConfig sets the values of dotted FTL configuration keys.
+func (c *piholeClient) SetConfig(ctx context.Context, feature string, values map[string]interface{}) error {
+	api, err := c.v6(feature)
+	if err != nil {
+		return err
+	}
+
+	// Setting the values again is harmless, so a write whose outcome is
+	// unknown is simply sent again
+	return c.write(ctx,
+		func() error { return api.setConfig(ctx, values) },
+		func() (bool, error) { return false, nil },
+	)
+}
+
+// localDNSDomain is the local domain of Pihole v6. Pihole 6.0 holds its
+// name in dns.domain, later versions in dns.domain.name.
+type localDNSDomain struct {
+	Name string
+	// Nested reports whether the name is held in dns.domain.name.
+	Nested bool
+}
+
+func (d *localDNSDomain) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &d.Name); err == nil {
+		return nil
+	}
+
+	var nested struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &nested); err != nil {
+		return fmt.Errorf("unexpected value for configuration dns.domain: %s", data)
+	}
+	d.Name, d.Nested = nested.Name, true
+
+	return nil
+}
+
+// Key returns the configuration key holding the name of the local domain.
+func (d localDNSDomain) Key() string {
+	if d.Nested {
+		return "dns.domain.name"
+	}
+
+	return "dns.domain"
+}
+
+// localDomain is the local domain and whether Pihole appends it to the short
+// host names of custom DNS records.
+type localDomain struct {
+	Name        string
+	ExpandHosts bool
+}
+
+// getLocalDomain returns the cached local domain of Pihole v6.
+func (c *piholeClient) getLocalDomain(ctx context.Context) (localDomain, error) {
+	domains, err := c.localDomainCache.get(func() ([]localDomain, error) {
+		var domain localDNSDomain
+		if err := c.GetConfig(ctx, "The local domain", "dns.domain", &domain); err != nil {
+			return nil, err
+		}
+		var expandHosts bool
+		if err := c.GetConfig(ctx, "The local domain", "dns.expandHosts", &expandHosts); err != nil {
+			return nil, err
+		}
+		return []localDomain{{Name: domain.Name, ExpandHosts: expandHosts}}, nil
+	})
+	if err != nil {
+		return localDomain{}, err
+	}
+
+	return domains[0], nil
+}
+
+// LocalDomain returns the local domain appended to the short host names of
+// DHCP clients, or an empty string on Pihole v5 whose API does not expose it.
+func (c *piholeClient) LocalDomain(ctx context.Context) (string, error) {
+	if c.api.Version() < 6 {
+		return "", nil
+	}
+
+	domain, err := c.getLocalDomain(ctx)
+
+	return domain.Name, err
+}
+
+// HostsDomain returns the local domain appended to the short host names of
+// custom DNS records, or an empty string when expand_hosts is disabled and on
+// Pihole v5.
+func (c *piholeClient) HostsDomain(ctx context.Context) (string, error) {
+	if c.api.Version() < 6 {
+		return "", nil
+	}
+
+	domain, err := c.getLocalDomain(ctx)
+	if err != nil || !domain.ExpandHosts {
+		return "", err
+	}
+
+	return domain.Name, nil
+}
+
+// configValue converts the string value of a configuration key to the type of
+// its current value: booleans, numbers, strings or arrays written in JSON.
+func configValue(current json.RawMessage, value string) (interface{}, error) {
+	var decoded interface{}
+	if err := json.Unmarshal(current, &decoded); err != nil {
+		return nil, err
+	}
+
+	switch decoded.(type) {
+	case bool:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", value)
+		}
+		return parsed, nil
+
+	case float64:
+		if parsed, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+			return parsed, nil
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return parsed, nil
+
+	case []interface{}:
+		var parsed []interface{}
+		if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+			return nil, fmt.Errorf("%q is not a JSON array", value)
+		}
+		return parsed, nil
+
+	case map[string]interface{}:
+		return nil, fmt.Errorf("the key holds a section, set the dotted keys of its values instead")
+	}
+
+	return value, nil
+}
+
+// configString returns the string form of a configuration value, the JSON
+// text of arrays and the text of other values.
+func configString(current json.RawMessage) (string, error) {
+	var decoded interface{}
+	if err := json.Unmarshal(current, &decoded); err != nil {
+		return "", err
+	}
+
+	switch decoded := decoded.(type) {
+	case string:
+		return decoded, nil
+	case nil:
+		return "", nil
+	case map[string]interface{}:
+		return "", fmt.Errorf("the key holds a section, set the dotted keys of its values instead")
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, current); err != nil {
+		return "", err
+	}
+
+	return compact.String(), nil
+}
+
+// configEqual reports whether the string value of a configuration key is
+// equal to its current value once converted to its type, so that true and
+// True, or 60 and 60.0, are the same value.
+func configEqual(current json.RawMessage, value string) bool {
+	converted, err := configValue(current, value)
+	if err != nil {
+		return false
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(current, &decoded); err != nil {
+		return false
+	}
+
+	left, _ := json.Marshal(converted)
+	right, _ := json.Marshal(decoded)
+
+	return bytes.Equal(left, right)
+}
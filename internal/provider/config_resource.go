@@ -0,0 +1,482 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &ConfigResource{}
+	_ resource.ResourceWithConfigure      = &ConfigResource{}
+	_ resource.ResourceWithImportState    = &ConfigResource{}
+	_ resource.ResourceWithValidateConfig = &ConfigResource{}
+	_ resource.ResourceWithModifyPlan     = &ConfigResource{}
+)
+
+// NewConfigResource is a helper function to simplify the provider implementation.
+func NewConfigResource() resource.Resource {
+	return &ConfigResource{}
+}
+
+// ConfigResource is the resource implementation.
+type ConfigResource struct {
+	client *piholeClient
+}
+
+// ConfigResourceModel maps the resource schema data.
+type ConfigResourceModel struct {
+	LastUpdated      types.String `tfsdk:"last_updated"`
+	Values           types.Map    `tfsdk:"values"`
+	RestoreOnDestroy types.Bool   `tfsdk:"restore_on_destroy"`
+	PreviousValues   types.Map    `tfsdk:"previous_values"`
+}
+
+// Metadata returns the resource type name.
+func (r *ConfigResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_config"
+}
+
+// Schema defines the schema for the resource.
+func (r *ConfigResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Values of dotted keys of the Pihole configuration tree, such as dns.bogusPriv or " +
+			"webserver.session.timeout. The other keys are left alone. Values are compared as the type of the key in " +
+			"Pihole, so that True and true, or 60 and 60.0, are the same value. Requires Pihole v6.",
+		Attributes: map[string]schema.Attribute{
+			"last_updated": schema.StringAttribute{
+				Description: "Timestamp of the last Terraform update of the values.",
+				Computed:    true,
+			},
+			"values": schema.MapAttribute{
+				Description: "Values by dotted key. Booleans, numbers and strings are written as text, arrays as JSON, " +
+					"such as [\"8.8.8.8\", \"8.8.4.4\"].",
+				ElementType: types.StringType,
+				Required:    true,
+			},
+			"restore_on_destroy": schema.BoolAttribute{
+				Description: "Whether to restore the values the keys had before being managed when the resource is " +
+					"destroyed or a key is removed from values. Defaults to false, leaving the values in place.",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"previous_values": schema.MapAttribute{
+				Description: "Values the keys had before being managed, restored with restore_on_destroy.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *ConfigResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*piholeClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *piholeClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// ValidateConfig checks the syntax of the keys.
+func (r *ConfigResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config ConfigResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || config.Values.IsUnknown() {
+		return
+	}
+
+	for key := range config.Values.Elements() {
+		if !configKeyRegexp.MatchString(key) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("values").AtMapKey(key),
+				"Invalid Configuration Key",
+				fmt.Sprintf("%q is not a dotted configuration key, such as dns.bogusPriv.", key),
+			)
+		}
+	}
+}
+
+// ModifyPlan checks the values against the type of their key in Pihole, and
+// keeps the previous values while the managed keys stay the same.
+func (r *ConfigResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan ConfigResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || plan.Values.IsUnknown() {
+		return
+	}
+
+	if !req.State.Raw.IsNull() {
+		var state ConfigResourceModel
+		diags = req.State.Get(ctx, &state)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if sameKeys(plan.Values, state.Values) {
+			diags = resp.Plan.SetAttribute(ctx, path.Root("previous_values"), state.PreviousValues)
+			resp.Diagnostics.Append(diags...)
+		}
+	}
+
+	// The provider is not configured yet when its configuration is unknown
+	if r.client == nil {
+		return
+	}
+
+	for key, element := range plan.Values.Elements() {
+		value, ok := element.(types.String)
+		if !ok || value.IsNull() || value.IsUnknown() || !configKeyRegexp.MatchString(key) {
+			continue
+		}
+
+		var current json.RawMessage
+		err := r.client.GetConfig(ctx, "pihole_config", key, &current)
+		if err == nil {
+			_, err = configValue(current, value.ValueString())
+		}
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("values").AtMapKey(key),
+				"Invalid Configuration Value",
+				fmt.Sprintf("Could not set %s: %s", key, err),
+			)
+		}
+	}
+}
+
+// sameKeys reports whether two maps hold the same keys.
+func sameKeys(a types.Map, b types.Map) bool {
+	if a.IsUnknown() || b.IsUnknown() || len(a.Elements()) != len(b.Elements()) {
+		return false
+	}
+	for key := range a.Elements() {
+		if _, ok := b.Elements()[key]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getCurrent reads the current values of keys.
+func (r *ConfigResource) getCurrent(ctx context.Context, keys []string) (map[string]json.RawMessage, error) {
+	current := make(map[string]json.RawMessage, len(keys))
+	for _, key := range keys {
+		var value json.RawMessage
+		if err := r.client.GetConfig(ctx, "pihole_config", key, &value); err != nil {
+			return nil, err
+		}
+		current[key] = value
+	}
+
+	return current, nil
+}
+
+// convert converts values to the type of their key in current into updates.
+func convert(current map[string]json.RawMessage, values map[string]string, updates map[string]interface{}) error {
+	for key, value := range values {
+		converted, err := configValue(current[key], value)
+		if err != nil {
+			return fmt.Errorf("could not set %s: %w", key, err)
+		}
+		updates[key] = converted
+	}
+
+	return nil
+}
+
+// mapKeys returns the sorted keys of values.
+func mapKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// apply sets the planned values, recording the previous value of the keys
+// not managed in state, and restoring the previous value of the keys removed
+// from values when restore_on_destroy is set.
+func (r *ConfigResource) apply(ctx context.Context, plan *ConfigResourceModel, state *ConfigResourceModel) error {
+	values := map[string]string{}
+	if diags := plan.Values.ElementsAs(ctx, &values, false); diags.HasError() {
+		return fmt.Errorf("could not read values")
+	}
+	stateValues, previous := map[string]string{}, map[string]string{}
+	if state != nil {
+		if diags := state.Values.ElementsAs(ctx, &stateValues, false); diags.HasError() {
+			return fmt.Errorf("could not read the state values")
+		}
+		if diags := state.PreviousValues.ElementsAs(ctx, &previous, false); diags.HasError() {
+			return fmt.Errorf("could not read the previous values")
+		}
+	}
+
+	restore := map[string]string{}
+	for key := range stateValues {
+		if _, ok := values[key]; !ok {
+			if value, ok := previous[key]; ok && plan.RestoreOnDestroy.ValueBool() {
+				restore[key] = value
+			}
+			delete(previous, key)
+		}
+	}
+
+	keys := mapKeys(values)
+	keys = append(keys, mapKeys(restore)...)
+	current, err := r.getCurrent(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range mapKeys(values) {
+		if _, ok := previous[key]; ok {
+			continue
+		}
+		value, err := configString(current[key])
+		if err != nil {
+			return fmt.Errorf("could not read %s: %w", key, err)
+		}
+		previous[key] = value
+	}
+
+	updates := map[string]interface{}{}
+	if err := convert(current, restore, updates); err != nil {
+		return err
+	}
+	if err := convert(current, values, updates); err != nil {
+		return err
+	}
+
+	ctx = tflog.SetField(ctx, "keys", mapKeys(values))
+	if err := r.client.SetConfig(ctx, "pihole_config", updates); err != nil {
+		return err
+	}
+
+	previousValues, diags := types.MapValueFrom(ctx, types.StringType, previous)
+	if diags.HasError() {
+		return fmt.Errorf("could not set the previous values")
+	}
+	plan.PreviousValues = previousValues
+	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+
+	return nil
+}
+
+// Create sets the values.
+func (r *ConfigResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan ConfigResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.apply(ctx, &plan, nil); err != nil {
+		resp.Diagnostics.AddError(
+			"Error setting Pihole configuration",
+			"Could not set the configuration values, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the values, keeping the text of values equal to the current
+// ones once converted to their type.
+func (r *ConfigResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state ConfigResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	values := map[string]string{}
+	diags = state.Values.ElementsAs(ctx, &values, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	current, err := r.getCurrent(ctx, mapKeys(values))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Pihole configuration",
+			"Could not read the configuration values: "+err.Error(),
+		)
+		return
+	}
+
+	for key, value := range values {
+		if configEqual(current[key], value) {
+			continue
+		}
+		if values[key], err = configString(current[key]); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Reading Pihole configuration",
+				fmt.Sprintf("Could not read %s: %s", key, err),
+			)
+			return
+		}
+	}
+
+	state.Values, diags = types.MapValueFrom(ctx, types.StringType, values)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update sets the changed values.
+func (r *ConfigResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state ConfigResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.apply(ctx, &plan, &state); err != nil {
+		resp.Diagnostics.AddError(
+			"Error updating Pihole configuration",
+			"Could not update the configuration values, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete restores the previous values with restore_on_destroy, and leaves the
+// values in place otherwise.
+func (r *ConfigResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state ConfigResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !state.RestoreOnDestroy.ValueBool() {
+		tflog.Info(ctx, "Leaving the configuration values in place")
+		return
+	}
+
+	err := func() error {
+		previous := map[string]string{}
+		if diags := state.PreviousValues.ElementsAs(ctx, &previous, false); diags.HasError() {
+			return fmt.Errorf("could not read the previous values")
+		}
+
+		current, err := r.getCurrent(ctx, mapKeys(previous))
+		if err != nil {
+			return err
+		}
+		updates := map[string]interface{}{}
+		if err := convert(current, previous, updates); err != nil {
+			return err
+		}
+
+		return r.client.SetConfig(ctx, "pihole_config", updates)
+	}()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting Pihole configuration",
+			"Could not restore the previous configuration values, unexpected error: "+err.Error(),
+		)
+	}
+}
+
+// ImportState adopts the current values of the comma separated keys of the
+// import ID, such as dns.bogusPriv,webserver.session.timeout.
+func (r *ConfigResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	values := map[string]string{}
+	err := func() error {
+		for _, key := range strings.Split(req.ID, ",") {
+			key = strings.TrimSpace(key)
+			if !configKeyRegexp.MatchString(key) {
+				return fmt.Errorf("%q is not a dotted configuration key, such as dns.bogusPriv", key)
+			}
+			values[key] = ""
+		}
+
+		current, err := r.getCurrent(ctx, mapKeys(values))
+		if err != nil {
+			return err
+		}
+		for key := range values {
+			if values[key], err = configString(current[key]); err != nil {
+				return fmt.Errorf("could not read %s: %w", key, err)
+			}
+		}
+
+		return nil
+	}()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Importing Pihole configuration",
+			"Could not import the configuration values: "+err.Error(),
+		)
+		return
+	}
+
+	state := ConfigResourceModel{
+		LastUpdated:      types.StringNull(),
+		RestoreOnDestroy: types.BoolValue(false),
+	}
+	// The current values are restored with restore_on_destroy
+	imported, diags := types.MapValueFrom(ctx, types.StringType, values)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.Values, state.PreviousValues = imported, imported
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,288 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestClientConfig(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	client := fake.client()
+
+	err := client.SetConfig(ctx, "test", map[string]interface{}{"dns.domain": "home.arpa", "dns.expandHosts": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var settings localDNSDomainSettings
+	if err := client.GetConfig(ctx, "test", "dns", &settings); err != nil {
+		t.Fatal(err)
+	}
+	expected := localDNSDomainSettings{Domain: localDNSDomain{Name: "home.arpa"}, ExpandHosts: true, BogusPriv: true}
+	if settings != expected {
+		t.Fatalf("expected %+v, got %+v", expected, settings)
+	}
+
+	if err := client.SetConfig(ctx, "test", map[string]interface{}{"dns.unknown": 1}); err == nil {
+		t.Fatal("expected an error setting an unknown key")
+	}
+}
+
+func TestClientLocalDomain(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	client := fake.client()
+
+	if domain, err := client.LocalDomain(ctx); err != nil || domain != "lan" {
+		t.Fatalf("expected the lan domain, got %q, %v", domain, err)
+	}
+
+	// Writes drop the cached domain
+	if err := client.SetConfig(ctx, "test", map[string]interface{}{"dns.domain": "home.arpa"}); err != nil {
+		t.Fatal(err)
+	}
+	if domain, err := client.LocalDomain(ctx); err != nil || domain != "home.arpa" {
+		t.Fatalf("expected the updated domain, got %q, %v", domain, err)
+	}
+
+	if domain, err := newFakePihole(t).client().LocalDomain(ctx); err != nil || domain != "" {
+		t.Fatalf("expected no local domain on Pihole v5, got %q, %v", domain, err)
+	}
+}
+
+func TestClientHostsDomain(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	client := fake.client()
+
+	// Short host names of custom DNS records are only qualified with
+	// expand_hosts
+	if domain, err := client.HostsDomain(ctx); err != nil || domain != "" {
+		t.Fatalf("expected no domain without expand_hosts, got %q, %v", domain, err)
+	}
+
+	if err := client.SetConfig(ctx, "test", map[string]interface{}{"dns.expandHosts": true}); err != nil {
+		t.Fatal(err)
+	}
+	if domain, err := client.HostsDomain(ctx); err != nil || domain != "lan" {
+		t.Fatalf("expected the lan domain with expand_hosts, got %q, %v", domain, err)
+	}
+	if domain, err := client.LocalDomain(ctx); err != nil || domain != "lan" {
+		t.Fatalf("expected the lan domain, got %q, %v", domain, err)
+	}
+}
+
+func TestLocalDnsDomainResourceApply(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	client := fake.client()
+	resource := &LocalDnsDomainResource{client: client}
+
+	model := LocalDnsDomainResourceModel{
+		Domain:      types.StringValue("home.arpa"),
+		ExpandHosts: types.BoolValue(true),
+	}
+	if err := resource.apply(ctx, &model); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unset settings are left unmanaged
+	expected := LocalDnsDomainResourceModel{
+		LastUpdated: model.LastUpdated,
+		Domain:      types.StringValue("home.arpa"),
+		ExpandHosts: types.BoolValue(true),
+	}
+	if model != expected {
+		t.Fatalf("expected %+v, got %+v", expected, model)
+	}
+
+	// Destroying restores the defaults of the domain and the managed settings
+	// only
+	if err := client.SetConfig(ctx, "test", map[string]interface{}{"dns.bogusPriv": false}); err != nil {
+		t.Fatal(err)
+	}
+	model.setSettings(localDNSDomainDefaults)
+	if err := resource.apply(ctx, &model); err != nil {
+		t.Fatal(err)
+	}
+	settings, err := resource.getSettings(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored := (localDNSDomainSettings{Domain: localDNSDomain{Name: "lan"}}); settings != restored {
+		t.Fatalf("expected %+v, got %+v", restored, settings)
+	}
+}
+
+func TestLocalDNSDomainVersions(t *testing.T) {
+	var flat, nested localDNSDomain
+	if err := json.Unmarshal([]byte(`"lan"`), &flat); err != nil || flat.Name != "lan" || flat.Key() != "dns.domain" {
+		t.Fatalf("unexpected Pihole 6.0 domain %+v, %v", flat, err)
+	}
+	if err := json.Unmarshal([]byte(`{"name":"lan","local":true}`), &nested); err != nil || nested.Name != "lan" || nested.Key() != "dns.domain.name" {
+		t.Fatalf("unexpected Pihole 6.1 domain %+v, %v", nested, err)
+	}
+}
+
+func TestFTLSettings(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	client := fake.client()
+
+	settings, err := getFTLSettings(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings != ftlSettingsDefaults {
+		t.Fatalf("expected the defaults %+v, got %+v", ftlSettingsDefaults, settings)
+	}
+
+	resource := &FtlSettingsResource{client: client}
+	model := FtlSettingsResourceModel{
+		RateLimitCount: types.Int64Value(500),
+		PrivacyLevel:   types.Int64Value(2),
+		QueryLogging:   types.BoolValue(false),
+	}
+	if err := resource.apply(ctx, &model); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unset settings are left unmanaged
+	expected := FtlSettingsResourceModel{
+		LastUpdated:    model.LastUpdated,
+		RateLimitCount: types.Int64Value(500),
+		PrivacyLevel:   types.Int64Value(2),
+		QueryLogging:   types.BoolValue(false),
+	}
+	if model != expected {
+		t.Fatalf("expected %+v, got %+v", expected, model)
+	}
+
+	// Destroying restores the defaults of the managed settings only
+	if err := client.SetConfig(ctx, "test", map[string]interface{}{"database.maxDBdays": 30}); err != nil {
+		t.Fatal(err)
+	}
+	model.setSettings(ftlSettingsDefaults)
+	if err := resource.apply(ctx, &model); err != nil {
+		t.Fatal(err)
+	}
+	settings, err = getFTLSettings(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored := (ftlSettings{RateLimitCount: 1000, RateLimitInterval: 60, PrivacyLevel: 0, QueryLogging: true, MaxDBDays: 30}); settings != restored {
+		t.Fatalf("expected %+v, got %+v", restored, settings)
+	}
+
+	if _, err := getFTLSettings(ctx, newFakePihole(t).client()); err == nil {
+		t.Fatal("expected an error on Pihole v5")
+	}
+}
+
+func TestConfigValue(t *testing.T) {
+	tests := []struct {
+		current  string
+		value    string
+		expected interface{}
+	}{
+		{`true`, "False", false},
+		{`1800`, "300", int64(300)},
+		{`0.5`, "0.25", 0.25},
+		{`"lan"`, "home.arpa", "home.arpa"},
+		{`["8.8.8.8"]`, `["1.1.1.1", "1.0.0.1"]`, []interface{}{"1.1.1.1", "1.0.0.1"}},
+	}
+	for _, test := range tests {
+		value, err := configValue(json.RawMessage(test.current), test.value)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(value, test.expected) {
+			t.Errorf("%s: expected %#v, got %#v", test.value, test.expected, value)
+		}
+	}
+
+	for current, value := range map[string]string{`true`: "yes", `60`: "sixty", `[]`: "8.8.8.8", `{"name":"lan"}`: "lan"} {
+		if _, err := configValue(json.RawMessage(current), value); err == nil {
+			t.Errorf("expected an error converting %q to the type of %s", value, current)
+		}
+	}
+}
+
+func TestConfigEqual(t *testing.T) {
+	for current, value := range map[string]string{`true`: "True", `60`: "60.0", `["a","b"]`: `[ "a", "b" ]`, `"lan"`: "lan"} {
+		if !configEqual(json.RawMessage(current), value) {
+			t.Errorf("expected %q to equal %s", value, current)
+		}
+	}
+	for current, value := range map[string]string{`true`: "false", `60`: "61", `["a","b"]`: `["b","a"]`, `"lan"`: "Lan"} {
+		if configEqual(json.RawMessage(current), value) {
+			t.Errorf("expected %q to differ from %s", value, current)
+		}
+	}
+
+	if value, err := configString(json.RawMessage(`[ "a", "b" ]`)); err != nil || value != `["a","b"]` {
+		t.Fatalf("expected the compact array, got %q, %v", value, err)
+	}
+}
+
+func TestConfigResourceApply(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	resource := &ConfigResource{client: fake.client()}
+
+	stringMap := func(values map[string]string) types.Map {
+		return types.MapValueMust(types.StringType, func() map[string]attr.Value {
+			elements := map[string]attr.Value{}
+			for key, value := range values {
+				elements[key] = types.StringValue(value)
+			}
+			return elements
+		}())
+	}
+
+	state := ConfigResourceModel{
+		Values:           stringMap(map[string]string{"dns.bogusPriv": "False", "webserver.session.timeout": "300"}),
+		RestoreOnDestroy: types.BoolValue(true),
+	}
+	if err := resource.apply(ctx, &state, nil); err != nil {
+		t.Fatal(err)
+	}
+	if expected := stringMap(map[string]string{"dns.bogusPriv": "true", "webserver.session.timeout": "1800"}); !state.PreviousValues.Equal(expected) {
+		t.Fatalf("expected the previous values %v, got %v", expected, state.PreviousValues)
+	}
+	if fake.config["dns.bogusPriv"] != false || fake.config["webserver.session.timeout"] != float64(300) {
+		t.Fatalf("unexpected configuration %v", fake.config)
+	}
+
+	// Removing a key restores it, adding one records its previous value
+	plan := ConfigResourceModel{
+		Values:           stringMap(map[string]string{"dns.bogusPriv": "false", "dns.upstreams": `["1.1.1.1"]`}),
+		RestoreOnDestroy: types.BoolValue(true),
+	}
+	if err := resource.apply(ctx, &plan, &state); err != nil {
+		t.Fatal(err)
+	}
+	if expected := stringMap(map[string]string{"dns.bogusPriv": "true", "dns.upstreams": `["8.8.8.8"]`}); !plan.PreviousValues.Equal(expected) {
+		t.Fatalf("expected the previous values %v, got %v", expected, plan.PreviousValues)
+	}
+	if fake.config["webserver.session.timeout"] != float64(1800) {
+		t.Fatalf("expected the removed key to be restored, got %v", fake.config["webserver.session.timeout"])
+	}
+	if !reflect.DeepEqual(fake.config["dns.upstreams"], []interface{}{"1.1.1.1"}) {
+		t.Fatalf("unexpected upstreams %v", fake.config["dns.upstreams"])
+	}
+
+	if err := resource.apply(ctx, &ConfigResourceModel{Values: stringMap(map[string]string{"dns.bogusPriv": "maybe"})}, nil); err == nil {
+		t.Fatal("expected an error converting an invalid boolean")
+	}
+	if err := resource.apply(ctx, &ConfigResourceModel{Values: stringMap(map[string]string{"dns": "true"})}, nil); err == nil {
+		t.Fatal("expected an error setting a section")
+	}
+}
@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// credentialCommandTimeout bounds the run of the credential_command helper,
+// which may prompt for an unlock.
+const credentialCommandTimeout = 2 * time.Minute
+
+// v5Token derives the Pihole v5 API token from the web password: the
+// double SHA-256 stored as WEBPASSWORD in setupVars.conf.
+func v5Token(password string) string {
+	if password == "" {
+		return ""
+	}
+
+	first := sha256.Sum256([]byte(password))
+	second := sha256.Sum256([]byte(hex.EncodeToString(first[:])))
+
+	return hex.EncodeToString(second[:])
+}
+
+// readSecretFile returns the secret stored in a file, such as a Docker or
+// Kubernetes secret, without its trailing newline.
+func readSecretFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	secret := strings.TrimRight(string(content), "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+
+	return secret, nil
+}
+
+// runCredentialCommand runs a helper such as pass or op and returns the secret
+// it prints on its standard output, without its trailing newline.
+func runCredentialCommand(ctx context.Context, command []string) (string, error) {
+	if len(command) == 0 || command[0] == "" {
+		return "", fmt.Errorf("the command is empty")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, credentialCommandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// Only stderr is reported, stdout holds the secret
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s failed: %w: %s", command[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	secret := strings.TrimRight(stdout.String(), "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("%s printed no secret", command[0])
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestV5Token(t *testing.T) {
+	// WEBPASSWORD of the docker_compose Pihole, as used in the examples
+	if got := v5Token("example"); got != "96cf46f9e9312ea9ad00f5f9e63b25643f701246357068549a6c2ea3d163bf1e" {
+		t.Fatalf("unexpected token %s", got)
+	}
+
+	if got := v5Token(""); got != "" {
+		t.Fatalf("expected no token without password, got %s", got)
+	}
+}
+
+func TestReadSecretFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := readSecretFile(path)
+	if err != nil || secret != "secret" {
+		t.Fatalf("expected the secret without newline, got %q (%v)", secret, err)
+	}
+
+	if err := os.WriteFile(path, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readSecretFile(path); err == nil {
+		t.Fatal("expected an error for an empty secret file")
+	}
+}
+
+func TestRunCredentialCommand(t *testing.T) {
+	ctx := context.Background()
+
+	secret, err := runCredentialCommand(ctx, []string{"sh", "-c", "echo secret"})
+	if err != nil || secret != "secret" {
+		t.Fatalf("expected the secret without newline, got %q (%v)", secret, err)
+	}
+
+	_, err = runCredentialCommand(ctx, []string{"sh", "-c", "echo leaked; echo locked >&2; exit 1"})
+	if err == nil || !strings.Contains(err.Error(), "locked") || strings.Contains(err.Error(), "leaked") {
+		t.Fatalf("expected an error reporting stderr only, got %v", err)
+	}
+
+	if _, err := runCredentialCommand(ctx, nil); err == nil {
+		t.Fatal("expected an error for an empty command")
+	}
+}
@@ -0,0 +1,256 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// dnsExtraRecordOptions maps the record types Pihole only serves through
+// dnsmasq options to the option declaring them.
+var dnsExtraRecordOptions = map[string]string{
+	"PTR": "ptr-record",
+	"TXT": "txt-record",
+	"SRV": "srv-host",
+	"MX":  "mx-host",
+}
+
+// dnsExtraRecordTypes are the supported record types, in documentation order.
+var dnsExtraRecordTypes = []string{"PTR", "TXT", "SRV", "MX"}
+
+// dnsExtraRecord is a DNS record declared by a line of the dnsmasq
+// configuration. Ports, priorities and weights are -1 when not set.
+type dnsExtraRecord struct {
+	Type     string
+	Name     string
+	Target   string
+	Text     string
+	Port     int64
+	Priority int64
+	Weight   int64
+}
+
+// Line returns the dnsmasq option declaring the record, such as
+// srv-host=_ldap._tcp.example.com,dc1.example.com,389,0,100.
+func (r dnsExtraRecord) Line() string {
+	fields := []string{r.Name}
+
+	switch r.Type {
+	case "PTR":
+		fields = append(fields, r.Target)
+	case "TXT":
+		fields = append(fields, `"`+r.Text+`"`)
+	case "SRV":
+		fields = append(fields, r.Target)
+		for _, value := range []int64{r.Port, r.Priority, r.Weight} {
+			if value < 0 {
+				break
+			}
+			fields = append(fields, strconv.FormatInt(value, 10))
+		}
+	case "MX":
+		fields = append(fields, r.Target)
+		if r.Priority >= 0 {
+			fields = append(fields, strconv.FormatInt(r.Priority, 10))
+		}
+	}
+
+	return dnsExtraRecordOptions[r.Type] + "=" + strings.Join(fields, ",")
+}
+
+// parseDNSExtraRecord parses a dnsmasq option declaring a record.
+func parseDNSExtraRecord(line string) (dnsExtraRecord, error) {
+	record := dnsExtraRecord{Port: -1, Priority: -1, Weight: -1}
+
+	option, value, _ := strings.Cut(strings.TrimSpace(line), "=")
+	for recordType, name := range dnsExtraRecordOptions {
+		if option == name {
+			record.Type = recordType
+		}
+	}
+	if record.Type == "" || value == "" {
+		return record, fmt.Errorf("%q is not a ptr-record, txt-record, srv-host or mx-host option", line)
+	}
+
+	fields := strings.Split(value, ",")
+	record.Name = fields[0]
+	if record.Type == "TXT" {
+		record.Text = strings.Trim(strings.TrimPrefix(value, record.Name+","), `"`)
+		return record, nil
+	}
+	if len(fields) > 1 {
+		record.Target = fields[1]
+	}
+
+	// Then come the port, priority and weight of SRV records, or the
+	// preference of MX records
+	var numbers []*int64
+	switch record.Type {
+	case "SRV":
+		numbers = []*int64{&record.Port, &record.Priority, &record.Weight}
+	case "MX":
+		numbers = []*int64{&record.Priority}
+	}
+	var extra []string
+	if len(fields) > 2 {
+		extra = fields[2:]
+	}
+	if len(extra) > len(numbers) {
+		return record, fmt.Errorf("%q has too many fields", line)
+	}
+	for i, field := range extra {
+		number, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return record, fmt.Errorf("%q has an invalid number %q", line, field)
+		}
+		*numbers[i] = number
+	}
+
+	return record, nil
+}
+
+// GetDnsmasqLines lists the extra dnsmasq configuration lines, fetched at
+// most once per cache TTL.
+func (c *piholeClient) GetDnsmasqLines(ctx context.Context) ([]string, error) {
+	return c.dnsmasqCache.get(func() ([]string, error) {
+		return c.fetchDnsmasqLines(ctx)
+	})
+}
+
+// fetchDnsmasqLines reads the extra dnsmasq configuration lines from Pihole.
+func (c *piholeClient) fetchDnsmasqLines(ctx context.Context) ([]string, error) {
+	api, err := c.v6("Extra DNS records")
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	if err := api.getConfig(ctx, "misc.dnsmasq_lines", &lines); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// AddDnsmasqLine adds an extra dnsmasq configuration line, unless present.
+func (c *piholeClient) AddDnsmasqLine(ctx context.Context, line string) error {
+	api, err := c.v6("Extra DNS records")
+	if err != nil {
+		return err
+	}
+
+	return c.write(ctx,
+		func() error {
+			found, err := c.hasDnsmasqLine(ctx, line)
+			if err != nil || found {
+				return err
+			}
+			return api.addConfigItem(ctx, "misc.dnsmasq_lines", line)
+		},
+		func() (bool, error) { return c.hasDnsmasqLine(ctx, line) },
+	)
+}
+
+// DeleteDnsmasqLine removes an extra dnsmasq configuration line, if present.
+func (c *piholeClient) DeleteDnsmasqLine(ctx context.Context, line string) error {
+	api, err := c.v6("Extra DNS records")
+	if err != nil {
+		return err
+	}
+
+	absent := func() (bool, error) {
+		found, err := c.hasDnsmasqLine(ctx, line)
+		return !found, err
+	}
+
+	return c.write(ctx,
+		func() error {
+			if done, err := absent(); err != nil || done {
+				return err
+			}
+			return api.deleteConfigItem(ctx, "misc.dnsmasq_lines", line)
+		},
+		absent,
+	)
+}
+
+// HasDnsmasqLine reports whether the extra dnsmasq configuration line
+// exists, reading the cached lines.
+func (c *piholeClient) HasDnsmasqLine(ctx context.Context, line string) (bool, error) {
+	lines, err := c.GetDnsmasqLines(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return containsDnsmasqLine(lines, line), nil
+}
+
+// hasDnsmasqLine reports whether the extra dnsmasq configuration line exists,
+// bypassing the cache as it checks the outcome of a write.
+func (c *piholeClient) hasDnsmasqLine(ctx context.Context, line string) (bool, error) {
+	lines, err := c.fetchDnsmasqLines(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return containsDnsmasqLine(lines, line), nil
+}
+
+// containsDnsmasqLine reports whether lines hold line, ignoring surrounding
+// blanks.
+func containsDnsmasqLine(lines []string, line string) bool {
+	for _, item := range lines {
+		if strings.TrimSpace(item) == line {
+			return true
+		}
+	}
+
+	return false
+}
+
+// qualifyHostname appends domain to a short host name, one without dots.
+// Names with dots, including absolute names such as nas., are returned
+// without their trailing dot.
+func qualifyHostname(host string, domain string) string {
+	if strings.Contains(host, ".") || domain == "" {
+		return strings.TrimSuffix(host, ".")
+	}
+
+	return host + "." + strings.Trim(domain, ".")
+}
+
+// reverseName returns the name of the PTR record of an IP address, under
+// in-addr.arpa for IPv4 and ip6.arpa for IPv6.
+func reverseName(ip string) (string, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return "", fmt.Errorf("%q is not an IP address", ip)
+	}
+
+	if v4 := addr.To4(); v4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa", v4[3], v4[2], v4[1], v4[0]), nil
+	}
+
+	// IPv6 addresses are reversed nibble by nibble
+	const digits = "0123456789abcdef"
+	labels := make([]string, 0, 2*net.IPv6len+1)
+	for i := net.IPv6len - 1; i >= 0; i-- {
+		labels = append(labels, string(digits[addr[i]&0x0f]), string(digits[addr[i]>>4]))
+	}
+	labels = append(labels, "ip6.arpa")
+
+	return strings.Join(labels, "."), nil
+}
+
+// ptrRecordLine returns the dnsmasq option declaring the PTR record of ip
+// pointing to domain.
+func ptrRecordLine(ip string, domain string) (string, error) {
+	name, err := reverseName(ip)
+	if err != nil {
+		return "", err
+	}
+
+	return dnsExtraRecord{Type: "PTR", Name: name, Target: domain, Port: -1, Priority: -1, Weight: -1}.Line(), nil
+}
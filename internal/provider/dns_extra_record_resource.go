@@ -0,0 +1,362 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &DnsExtraRecordResource{}
+	_ resource.ResourceWithConfigure      = &DnsExtraRecordResource{}
+	_ resource.ResourceWithValidateConfig = &DnsExtraRecordResource{}
+	_ resource.ResourceWithImportState    = &DnsExtraRecordResource{}
+)
+
+// NewDnsExtraRecordResource is a helper function to simplify the provider implementation.
+func NewDnsExtraRecordResource() resource.Resource {
+	return &DnsExtraRecordResource{}
+}
+
+// DnsExtraRecordResource is the resource implementation.
+type DnsExtraRecordResource struct {
+	client *piholeClient
+}
+
+// DnsExtraRecordResourceModel maps the resource schema data.
+type DnsExtraRecordResourceModel struct {
+	LastUpdated types.String `tfsdk:"last_updated"`
+	Type        types.String `tfsdk:"type"`
+	Name        types.String `tfsdk:"name"`
+	Target      types.String `tfsdk:"target"`
+	Text        types.String `tfsdk:"text"`
+	Port        types.Int64  `tfsdk:"port"`
+	Priority    types.Int64  `tfsdk:"priority"`
+	Weight      types.Int64  `tfsdk:"weight"`
+	Line        types.String `tfsdk:"line"`
+}
+
+// record returns the record described by the model.
+func (m DnsExtraRecordResourceModel) record() dnsExtraRecord {
+	record := dnsExtraRecord{
+		Type:     m.Type.ValueString(),
+		Name:     m.Name.ValueString(),
+		Target:   m.Target.ValueString(),
+		Text:     m.Text.ValueString(),
+		Port:     -1,
+		Priority: -1,
+		Weight:   -1,
+	}
+	if !m.Port.IsNull() {
+		record.Port = m.Port.ValueInt64()
+	}
+	if !m.Priority.IsNull() {
+		record.Priority = m.Priority.ValueInt64()
+	}
+	if !m.Weight.IsNull() {
+		record.Weight = m.Weight.ValueInt64()
+	}
+
+	return record
+}
+
+// setRecord sets the attributes of the model describing the record, leaving
+// the line as found in Pihole.
+func (m *DnsExtraRecordResourceModel) setRecord(record dnsExtraRecord) {
+	optionalString := func(value string) types.String {
+		if value == "" {
+			return types.StringNull()
+		}
+		return types.StringValue(value)
+	}
+	optionalInt64 := func(value int64) types.Int64 {
+		if value < 0 {
+			return types.Int64Null()
+		}
+		return types.Int64Value(value)
+	}
+
+	m.Type = types.StringValue(record.Type)
+	m.Name = types.StringValue(record.Name)
+	m.Target = optionalString(record.Target)
+	m.Text = optionalString(record.Text)
+	m.Port = optionalInt64(record.Port)
+	m.Priority = optionalInt64(record.Priority)
+	m.Weight = optionalInt64(record.Weight)
+}
+
+// Metadata returns the resource type name.
+func (r *DnsExtraRecordResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_dns_extra_record"
+}
+
+// Schema defines the schema for the resource.
+func (r *DnsExtraRecordResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	replace := []planmodifier.String{stringplanmodifier.RequiresReplace()}
+	replaceInt64 := []planmodifier.Int64{int64planmodifier.RequiresReplace()}
+
+	resp.Schema = schema.Schema{
+		Description: "PTR, TXT, SRV or MX record served by Pihole, declared in the misc.dnsmasq_lines setting. Requires Pihole v6.",
+		Attributes: map[string]schema.Attribute{
+			"last_updated": schema.StringAttribute{
+				Description: "Timestamp of the last Terraform update of the record.",
+				Computed:    true,
+			},
+			"type": schema.StringAttribute{
+				Description: "Type of the record: " + strings.Join(dnsExtraRecordTypes, ", ") + ".",
+				Required:    true,
+				Validators: []validator.String{
+					oneOfValidator{values: dnsExtraRecordTypes},
+				},
+				PlanModifiers: replace,
+			},
+			"name": schema.StringAttribute{
+				Description:   "Name of the record, such as 10.1.168.192.in-addr.arpa for PTR records or _ldap._tcp.example.com for SRV records.",
+				Required:      true,
+				PlanModifiers: replace,
+			},
+			"target": schema.StringAttribute{
+				Description:   "Host name the PTR record points to, host serving the SRV record, or mail exchanger of the MX record. Required for PTR, SRV and MX records.",
+				Optional:      true,
+				PlanModifiers: replace,
+			},
+			"text": schema.StringAttribute{
+				Description:   "Text of the TXT record, without double quotes. Required for TXT records.",
+				Optional:      true,
+				PlanModifiers: replace,
+			},
+			"port": schema.Int64Attribute{
+				Description:   "Port of the SRV record.",
+				Optional:      true,
+				PlanModifiers: replaceInt64,
+			},
+			"priority": schema.Int64Attribute{
+				Description:   "Priority of the SRV record, which requires port, or preference of the MX record.",
+				Optional:      true,
+				PlanModifiers: replaceInt64,
+			},
+			"weight": schema.Int64Attribute{
+				Description:   "Weight of the SRV record, which requires priority.",
+				Optional:      true,
+				PlanModifiers: replaceInt64,
+			},
+			"line": schema.StringAttribute{
+				Description: "dnsmasq option declaring the record, also used to import it.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *DnsExtraRecordResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*piholeClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *piholeClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// ValidateConfig checks that the attributes set match the record type.
+func (r *DnsExtraRecordResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config DnsExtraRecordResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || config.Type.IsUnknown() {
+		return
+	}
+
+	recordType := config.Type.ValueString()
+	attributes := map[string]bool{
+		"target":   !config.Target.IsNull(),
+		"text":     !config.Text.IsNull(),
+		"port":     !config.Port.IsNull(),
+		"priority": !config.Priority.IsNull(),
+		"weight":   !config.Weight.IsNull(),
+	}
+	required := map[string][]string{"PTR": {"target"}, "TXT": {"text"}, "SRV": {"target"}, "MX": {"target"}}[recordType]
+	allowed := map[string][]string{
+		"PTR": {"target"},
+		"TXT": {"text"},
+		"SRV": {"target", "port", "priority", "weight"},
+		"MX":  {"target", "priority"},
+	}[recordType]
+
+	for _, name := range required {
+		if !attributes[name] {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(name),
+				"Missing Attribute",
+				fmt.Sprintf("%s records require %s.", recordType, name),
+			)
+		}
+	}
+	for name, set := range attributes {
+		if set && !contains(allowed, name) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(name),
+				"Unexpected Attribute",
+				fmt.Sprintf("%s records do not support %s.", recordType, name),
+			)
+		}
+	}
+
+	// Values are separated by commas, and later SRV values need the earlier ones
+	if strings.ContainsAny(config.Name.ValueString()+config.Target.ValueString(), `,"`) {
+		resp.Diagnostics.AddAttributeError(path.Root("name"), "Invalid Record", "Names and targets must not contain commas or double quotes.")
+	}
+	if strings.Contains(config.Text.ValueString(), `"`) {
+		resp.Diagnostics.AddAttributeError(path.Root("text"), "Invalid Record", "The text must not contain double quotes.")
+	}
+	if recordType == "SRV" && attributes["priority"] && !attributes["port"] {
+		resp.Diagnostics.AddAttributeError(path.Root("priority"), "Missing Attribute", "The priority of SRV records requires port.")
+	}
+	if recordType == "SRV" && attributes["weight"] && !attributes["priority"] {
+		resp.Diagnostics.AddAttributeError(path.Root("weight"), "Missing Attribute", "The weight of SRV records requires priority.")
+	}
+}
+
+// contains reports whether values holds value.
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Create a new resource.
+func (r *DnsExtraRecordResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan DnsExtraRecordResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	line := plan.record().Line()
+	ctx = tflog.SetField(ctx, "line", line)
+
+	// An identical line already present is adopted
+	err := r.client.AddDnsmasqLine(ctx, line)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating extra DNS record",
+			"Could not add dnsmasq line "+line+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	plan.Line = types.StringValue(line)
+	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read resource information.
+func (r *DnsExtraRecordResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state DnsExtraRecordResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	line := state.Line.ValueString()
+	found, err := r.client.HasDnsmasqLine(ctx, line)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Pihole extra DNS record",
+			"Could not read dnsmasq line "+line+": "+err.Error(),
+		)
+		return
+	}
+
+	// Plan to add the line again when it was removed outside of Terraform
+	if !found {
+		tflog.Warn(ctx, "Extra DNS record not found, removing it from the state", map[string]any{"line": line})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	record, err := parseDNSExtraRecord(line)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("line"), "Invalid Extra DNS Record", err.Error())
+		return
+	}
+	state.setRecord(record)
+	state.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *DnsExtraRecordResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Update function will never be triggered as any change replaces the line
+}
+
+func (r *DnsExtraRecordResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Retrieve values from state
+	var state DnsExtraRecordResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteDnsmasqLine(ctx, state.Line.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting extra DNS record",
+			"Could not delete dnsmasq line "+state.Line.ValueString()+", unexpected error: "+err.Error(),
+		)
+	}
+}
+
+// ImportState imports a record from its dnsmasq line, such as
+// mx-host=example.com,mail.example.com,10.
+func (r *DnsExtraRecordResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	record, err := parseDNSExtraRecord(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Import ID", "The import ID must be a dnsmasq option declaring the record: "+err.Error())
+		return
+	}
+
+	var state DnsExtraRecordResourceModel
+	state.setRecord(record)
+	state.Line = types.StringValue(strings.TrimSpace(req.ID))
+	state.LastUpdated = types.StringNull()
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDNSExtraRecordLine(t *testing.T) {
+	tests := map[string]dnsExtraRecord{
+		"ptr-record=10.1.168.192.in-addr.arpa,nas.lan":              {Type: "PTR", Name: "10.1.168.192.in-addr.arpa", Target: "nas.lan", Port: -1, Priority: -1, Weight: -1},
+		`txt-record=example.com,"v=spf1 mx -all"`:                   {Type: "TXT", Name: "example.com", Text: "v=spf1 mx -all", Port: -1, Priority: -1, Weight: -1},
+		"srv-host=_ldap._tcp.example.com,dc1.example.com,389,0,100": {Type: "SRV", Name: "_ldap._tcp.example.com", Target: "dc1.example.com", Port: 389, Priority: 0, Weight: 100},
+		"srv-host=_kerberos._udp.example.com,dc1.example.com,88":    {Type: "SRV", Name: "_kerberos._udp.example.com", Target: "dc1.example.com", Port: 88, Priority: -1, Weight: -1},
+		"mx-host=example.com,mail.example.com,10":                   {Type: "MX", Name: "example.com", Target: "mail.example.com", Port: -1, Priority: 10, Weight: -1},
+		"mx-host=example.com,mail.example.com":                      {Type: "MX", Name: "example.com", Target: "mail.example.com", Port: -1, Priority: -1, Weight: -1},
+	}
+
+	for line, record := range tests {
+		if got := record.Line(); got != line {
+			t.Errorf("expected the line %q, got %q", line, got)
+		}
+		parsed, err := parseDNSExtraRecord(line)
+		if err != nil {
+			t.Errorf("%s: %s", line, err)
+		}
+		if parsed != record {
+			t.Errorf("%s: expected %+v, got %+v", line, record, parsed)
+		}
+	}
+
+	for _, line := range []string{"address=/example.com/1.2.3.4", "mx-host=", "mx-host=a,b,10,20", "srv-host=a,b,port"} {
+		if _, err := parseDNSExtraRecord(line); err == nil {
+			t.Errorf("expected an error parsing %q", line)
+		}
+	}
+}
+
+func TestClientDnsmasqLines(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	client := fake.client()
+	line := "srv-host=_ldap._tcp.example.com,dc1.example.com,389"
+
+	// Adding and deleting twice is idempotent
+	for i := 0; i < 2; i++ {
+		if err := client.AddDnsmasqLine(ctx, line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines, err := client.GetDnsmasqLines(ctx)
+	if err != nil || len(lines) != 1 || lines[0] != line {
+		t.Fatalf("expected the line to be added once, got %v, %v", lines, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := client.DeleteDnsmasqLine(ctx, line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if found, err := client.hasDnsmasqLine(ctx, line); err != nil || found {
+		t.Fatalf("expected the line to be deleted, got %t, %v", found, err)
+	}
+
+	if err := newFakePihole(t).client().AddDnsmasqLine(ctx, line); err == nil {
+		t.Fatal("expected an error on Pihole v5")
+	}
+}
+
+func TestClientDnsmasqLinesCache(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	client := fake.client()
+	line := "ptr-record=20.1.168.192.in-addr.arpa,nas.lan"
+
+	// The reads of a refresh share a single fetch
+	for i := 0; i < 5; i++ {
+		if found, err := client.HasDnsmasqLine(ctx, line); err != nil || found {
+			t.Fatalf("expected the line to be missing, got %t, %v", found, err)
+		}
+	}
+	if fetches := fake.count("misc.dnsmasq_lines/get"); fetches != 1 {
+		t.Fatalf("expected a single fetch, got %d", fetches)
+	}
+
+	// Writes drop the cached lines
+	if err := client.AddDnsmasqLine(ctx, line); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := client.HasDnsmasqLine(ctx, line); err != nil || !found {
+		t.Fatalf("expected the added line to be read, got %t, %v", found, err)
+	}
+}
+
+func TestReverseName(t *testing.T) {
+	tests := map[string]string{
+		"192.168.1.10":       "10.1.168.192.in-addr.arpa",
+		"::ffff:10.0.0.1":    "1.0.0.10.in-addr.arpa",
+		"2001:db8::567:89ab": "b.a.9.8.7.6.5.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa",
+	}
+
+	for ip, expected := range tests {
+		if got, err := reverseName(ip); err != nil || got != expected {
+			t.Errorf("reverseName(%q): expected %q, got %q, %v", ip, expected, got, err)
+		}
+	}
+
+	if _, err := reverseName("host.lan"); err == nil {
+		t.Error("expected an error for a host name")
+	}
+}
+
+func TestPtrRecordLine(t *testing.T) {
+	line, err := ptrRecordLine("192.168.1.10", "nas.lan")
+	if err != nil || line != "ptr-record=10.1.168.192.in-addr.arpa,nas.lan" {
+		t.Fatalf("unexpected PTR line %q, %v", line, err)
+	}
+}
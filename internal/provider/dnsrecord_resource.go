@@ -2,21 +2,30 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/NicoFgrx/pihole-api-go/api"
 	pihole "github.com/NicoFgrx/pihole-api-go/api"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
+// stalePtrPrivateKey is the private data key holding the former fqdn a PTR
+// record still points to, once the local domain changed.
+const stalePtrPrivateKey = "stale_ptr_fqdn"
+
 // Ensure the implementation satisfies the expected interfaces.
 var (
 	_ resource.Resource                = &dnsrecordResource{}
@@ -31,7 +40,7 @@ func NewDnsRecordResource() resource.Resource {
 
 // dnsrecordResource is the resource implementation.
 type dnsrecordResource struct {
-	client *pihole.Client
+	client *piholeClient
 }
 
 // dnsrecordResourceModel maps the resource schema data.
@@ -40,11 +49,13 @@ type dnsRecordResourceModel struct {
 	LastUpdated types.String `tfsdk:"last_updated"`
 	Domain      types.String `tfsdk:"domain"`
 	Ip          types.String `tfsdk:"ip"`
+	CreatePtr   types.Bool   `tfsdk:"create_ptr"`
+	PtrName     types.String `tfsdk:"ptr_name"`
+	Fqdn        types.String `tfsdk:"fqdn"`
 }
 
 // Metadata returns the resource type name.
 func (r *dnsrecordResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	fmt.Println(req.ProviderTypeName)
 	resp.TypeName = req.ProviderTypeName + "_dnsrecord"
 }
 
@@ -59,10 +70,13 @@ func (r *dnsrecordResource) Schema(_ context.Context, _ resource.SchemaRequest,
 			},
 			"domain": schema.StringAttribute{
 				Required:    true,
-				Description: "FQDN of the Custom DNS Record",
+				Description: "FQDN or short host name of the Custom DNS Record",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Validators: []validator.String{
+					hostnameValidator{},
+				},
 			},
 			"ip": schema.StringAttribute{
 				Required:    true,
@@ -70,6 +84,29 @@ func (r *dnsrecordResource) Schema(_ context.Context, _ resource.SchemaRequest,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Validators: []validator.String{
+					ipAddressValidator{},
+				},
+			},
+			"create_ptr": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Whether to maintain the PTR record resolving the IP address back to the domain. Requires Pihole v6. A PTR record left pointing to a former fqdn, once the local domain changed, is replaced on the next apply.",
+			},
+			"fqdn": schema.StringAttribute{
+				Computed:    true,
+				Description: "Fully qualified domain name of the record, also the target of the PTR record: the domain, qualified with the local domain of Pihole v6 when it is a short host name and expand_hosts is enabled. The domain is kept unchanged otherwise.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"ptr_name": schema.StringAttribute{
+				Computed:    true,
+				Description: "Reverse name of the IP address, under in-addr.arpa or ip6.arpa, holding the PTR record.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
 	}
@@ -81,12 +118,12 @@ func (r *dnsrecordResource) Configure(_ context.Context, req resource.ConfigureR
 		return
 	}
 
-	client, ok := req.ProviderData.(*pihole.Client)
+	client, ok := req.ProviderData.(*piholeClient)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *pihole.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *piholeClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
@@ -111,18 +148,53 @@ func (r *dnsrecordResource) Create(ctx context.Context, req resource.CreateReque
 		IP:     plan.Ip.ValueString(),
 	}
 
-	ctx = tflog.SetField(ctx, "api", r.client.APIKey)
-	ctx = tflog.SetField(ctx, "url", r.client.BaseURL)
 	ctx = tflog.SetField(ctx, "domain", data.Domain)
 	ctx = tflog.SetField(ctx, "ip", data.IP)
 
+	ptrName, err := reverseName(data.IP)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("ip"), "Invalid IP Address", err.Error())
+		return
+	}
+	plan.PtrName = types.StringValue(ptrName)
+
+	fqdn, err := r.fqdn(ctx, data.Domain)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading local domain",
+			"Could not read the local domain qualifying "+data.Domain+": "+err.Error(),
+		)
+		return
+	}
+	plan.Fqdn = types.StringValue(fqdn)
+
+	// Fail before creating the record when the PTR cannot be created
+	if plan.CreatePtr.ValueBool() {
+		if _, err := r.client.v6("PTR records"); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("create_ptr"), "Unable to Create PTR Record", err.Error())
+			return
+		}
+	}
+
 	// Create new dns record
-	err := r.client.AddCustomDNS(&data)
+	err = r.client.AddCustomDNS(ctx, &data)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error creating customdns",
 			"Could not create customdns, unexpected error: "+err.Error(),
 		)
+		return
+	}
+
+	if plan.CreatePtr.ValueBool() && !resp.Diagnostics.HasError() {
+		if err := r.setPtr(ctx, data.IP, fqdn, true); err != nil {
+			plan.CreatePtr = types.BoolValue(false)
+			resp.Diagnostics.AddAttributeError(
+				path.Root("create_ptr"),
+				"Error creating PTR record",
+				"Could not create the PTR record of "+data.IP+", unexpected error: "+err.Error(),
+			)
+		}
 	}
 
 	// Map response body to schema and populate Computed attribute values
@@ -150,7 +222,7 @@ func (r *dnsrecordResource) Read(ctx context.Context, req resource.ReadRequest,
 	}
 
 	// Get refresh dns value
-	dnsrecord, err := r.client.GetCustomDNS(state.Domain.ValueString())
+	dnsrecord, err := r.getRecord(ctx, state)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Pihole DNSRecord",
@@ -161,6 +233,55 @@ func (r *dnsrecordResource) Read(ctx context.Context, req resource.ReadRequest,
 
 	state.Domain = types.StringValue(dnsrecord.Domain)
 	state.Ip = types.StringValue(dnsrecord.IP)
+
+	ptrName, err := reverseName(dnsrecord.IP)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("ip"), "Invalid IP Address", err.Error())
+		return
+	}
+	state.PtrName = types.StringValue(ptrName)
+
+	// The PTR record points to the fqdn of the state, which is no longer the
+	// fqdn of the record once the local domain changed
+	ptrFqdn, err := r.stateFqdn(ctx, state)
+	fqdn := ptrFqdn
+	if err == nil {
+		fqdn, err = r.fqdn(ctx, dnsrecord.Domain)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Pihole local domain",
+			"Could not read the local domain qualifying "+dnsrecord.Domain+": "+err.Error(),
+		)
+		return
+	}
+	state.Fqdn = types.StringValue(fqdn)
+
+	// Only look for the PTR record when it is managed, or after an import
+	switch {
+	case state.CreatePtr.IsNull() && r.client.api.Version() < 6:
+		state.CreatePtr = types.BoolValue(false)
+	case state.CreatePtr.IsNull() || state.CreatePtr.ValueBool():
+		line, _ := ptrRecordLine(dnsrecord.IP, ptrFqdn)
+		found, err := r.client.HasDnsmasqLine(ctx, line)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Reading Pihole PTR record",
+				"Could not read the PTR record of "+dnsrecord.IP+": "+err.Error(),
+			)
+			return
+		}
+
+		// A PTR record pointing to a former fqdn is reported missing, and
+		// replaced by the next apply
+		if found && ptrFqdn != fqdn {
+			data, _ := json.Marshal(ptrFqdn)
+			resp.Diagnostics.Append(resp.Private.SetKey(ctx, stalePtrPrivateKey, data)...)
+			found = false
+		}
+		state.CreatePtr = types.BoolValue(found)
+	}
+
 	state.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
 
 	// Set refreshed state
@@ -172,9 +293,110 @@ func (r *dnsrecordResource) Read(ctx context.Context, req resource.ReadRequest,
 
 }
 
+// Update creates or deletes the PTR record, any other change replacing the
+// record because of Pihole API limitation.
 func (r *dnsrecordResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Update function will never be triggered because of Pihole API limitation
-	return
+	var plan, state dnsRecordResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.CreatePtr.ValueBool() != state.CreatePtr.ValueBool() {
+		fqdn, err := r.stateFqdn(ctx, state)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading local domain",
+				"Could not read the local domain qualifying "+state.Domain.ValueString()+": "+err.Error(),
+			)
+			return
+		}
+		plan.Fqdn = types.StringValue(fqdn)
+
+		// Replace the PTR record left pointing to a former fqdn, if any
+		stale, diags := r.stalePtrFqdn(ctx, req.Private)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if stale != "" {
+			err = r.setPtr(ctx, plan.Ip.ValueString(), stale, false)
+		}
+		if err == nil {
+			err = r.setPtr(ctx, plan.Ip.ValueString(), fqdn, plan.CreatePtr.ValueBool())
+		}
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("create_ptr"),
+				"Error updating PTR record",
+				"Could not update the PTR record of "+plan.Ip.ValueString()+", unexpected error: "+err.Error(),
+			)
+			return
+		}
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, stalePtrPrivateKey, nil)...)
+	}
+
+	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+
+	diags := resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// fqdn qualifies a short host name with the local domain when Pihole does,
+// with expand_hosts enabled, and returns it unchanged otherwise.
+func (r *dnsrecordResource) fqdn(ctx context.Context, domain string) (string, error) {
+	if strings.Contains(domain, ".") {
+		return domain, nil
+	}
+
+	hostsDomain, err := r.client.HostsDomain(ctx)
+	if err != nil {
+		return domain, err
+	}
+
+	return qualifyHostname(domain, hostsDomain), nil
+}
+
+// stateFqdn returns the fqdn the PTR record of the state points to, computing
+// it for states saved before fqdn existed.
+func (r *dnsrecordResource) stateFqdn(ctx context.Context, state dnsRecordResourceModel) (string, error) {
+	if !state.Fqdn.IsNull() && !state.Fqdn.IsUnknown() {
+		return state.Fqdn.ValueString(), nil
+	}
+
+	return r.fqdn(ctx, state.Domain.ValueString())
+}
+
+// stalePtrFqdn returns the former fqdn a PTR record still points to, if any.
+func (r *dnsrecordResource) stalePtrFqdn(ctx context.Context, private interface {
+	GetKey(context.Context, string) ([]byte, diag.Diagnostics)
+}) (string, diag.Diagnostics) {
+	data, diags := private.GetKey(ctx, stalePtrPrivateKey)
+	if diags.HasError() || data == nil {
+		return "", diags
+	}
+
+	var fqdn string
+	if err := json.Unmarshal(data, &fqdn); err != nil {
+		diags.AddError("Error Reading Pihole PTR record", err.Error())
+	}
+
+	return fqdn, diags
+}
+
+// setPtr creates or deletes the PTR record pointing ip to domain.
+func (r *dnsrecordResource) setPtr(ctx context.Context, ip string, domain string, create bool) error {
+	line, err := ptrRecordLine(ip, domain)
+	if err != nil {
+		return err
+	}
+
+	if create {
+		return r.client.AddDnsmasqLine(ctx, line)
+	}
+
+	return r.client.DeleteDnsmasqLine(ctx, line)
 }
 
 func (r *dnsrecordResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -192,8 +414,39 @@ func (r *dnsrecordResource) Delete(ctx context.Context, req resource.DeleteReque
 		IP:     state.Ip.ValueString(),
 	}
 
+	// Delete the PTR record, and the one left pointing to a former fqdn
+	stale, diags := r.stalePtrFqdn(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var ptrFqdns []string
+	if stale != "" {
+		ptrFqdns = append(ptrFqdns, stale)
+	}
+	if state.CreatePtr.ValueBool() {
+		fqdn, err := r.stateFqdn(ctx, state)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error reading local domain",
+				"Could not read the local domain qualifying "+to_delete.Domain+": "+err.Error(),
+			)
+			return
+		}
+		ptrFqdns = append(ptrFqdns, fqdn)
+	}
+	for _, fqdn := range ptrFqdns {
+		if err := r.setPtr(ctx, to_delete.IP, fqdn, false); err != nil {
+			resp.Diagnostics.AddError(
+				"Error Deleting PTR Record",
+				"Could not delete the PTR record of "+to_delete.IP+", unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
 	// Delete existing record
-	err := r.client.DeleteCustomDNS(&to_delete)
+	err := r.client.DeleteCustomDNS(ctx, &to_delete)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Deleting DNS Record ",
@@ -204,7 +457,30 @@ func (r *dnsrecordResource) Delete(ctx context.Context, req resource.DeleteReque
 
 }
 
+// getRecord returns the custom DNS record of the state. The IP address tells
+// apart the records of a domain having several, the first record of the
+// domain being returned when none has it.
+func (r *dnsrecordResource) getRecord(ctx context.Context, state dnsRecordResourceModel) (pihole.DNSRecordParams, error) {
+	dnsrecords, err := r.client.GetAllCustomDNS(ctx)
+	if err != nil {
+		return pihole.DNSRecordParams{}, err
+	}
+
+	for _, item := range dnsrecords {
+		if strings.EqualFold(item.Domain, state.Domain.ValueString()) && item.IP == state.Ip.ValueString() {
+			return item, nil
+		}
+	}
+
+	return r.client.GetCustomDNS(ctx, state.Domain.ValueString())
+}
+
+// ImportState takes the domain as import ID, or the domain and the IP address
+// separated by a comma for a domain having several records.
 func (r *dnsrecordResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	domain, ip, found := strings.Cut(req.ID, ",")
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("domain"), domain)...)
+	if found {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ip"), ip)...)
+	}
 }
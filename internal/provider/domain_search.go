@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// domainSearchLimit is the maximum number of matches of each kind returned
+// by a search.
+const domainSearchLimit = 1000
+
+// domainSearch lists the entries of the Pihole lists matching a domain.
+type domainSearch struct {
+	// Domains are the exact and regex entries of the domain lists.
+	Domains []domainSearchEntry `json:"domains"`
+	// Gravity are the entries of the adlists, with their list address.
+	Gravity []domainSearchEntry `json:"gravity"`
+}
+
+// domainSearchEntry is an entry of a domain list or an adlist matching a
+// searched domain.
+type domainSearchEntry struct {
+	Domain  string  `json:"domain"`
+	Address string  `json:"address"`
+	Comment *string `json:"comment"`
+	Enabled bool    `json:"enabled"`
+	// Type is allow or deny for domains, and allow or block for adlists.
+	Type string `json:"type"`
+	// Kind is exact or regex for domains.
+	Kind string `json:"kind"`
+}
+
+// BlockedBy returns what blocks the domain, exact, gravity or regex, or an
+// empty string when it is not blocked, following the order in which FTL
+// checks the lists: allowed domains, denied domains, then gravity unless an
+// allowlist holds the domain, and finally denied regex. Group assignments
+// are ignored.
+func (s domainSearch) BlockedBy() string {
+	enabled := func(entries []domainSearchEntry, match func(domainSearchEntry) bool) bool {
+		for _, entry := range entries {
+			if entry.Enabled && match(entry) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case enabled(s.Domains, func(e domainSearchEntry) bool { return e.Type == "allow" }):
+		return ""
+	case enabled(s.Domains, func(e domainSearchEntry) bool { return e.Type == "deny" && e.Kind == "exact" }):
+		return "exact"
+	case enabled(s.Gravity, func(e domainSearchEntry) bool { return e.Type == "block" }) &&
+		!enabled(s.Gravity, func(e domainSearchEntry) bool { return e.Type == "allow" }):
+		return "gravity"
+	case enabled(s.Domains, func(e domainSearchEntry) bool { return e.Type == "deny" && e.Kind == "regex" }):
+		return "regex"
+	}
+
+	return ""
+}
+
+// SearchDomain lists the entries of the domain lists and adlists matching a
+// domain, or containing it when partial is set.
+func (c *piholeClient) SearchDomain(ctx context.Context, domain string, partial bool) (domainSearch, error) {
+	api, err := c.v6("Domain searches")
+	if err != nil {
+		return domainSearch{}, err
+	}
+
+	return api.searchDomain(ctx, domain, partial)
+}
+
+// searchDomain searches the lists for a domain.
+func (a *v6API) searchDomain(ctx context.Context, domain string, partial bool) (domainSearch, error) {
+	params := url.Values{
+		"partial": {strconv.FormatBool(partial)},
+		"N":       {strconv.Itoa(domainSearchLimit)},
+	}
+
+	var answer struct {
+		Search domainSearch `json:"search"`
+	}
+	if err := a.do(ctx, http.MethodGet, "/search/"+url.PathEscape(domain)+"?"+params.Encode(), nil, &answer); err != nil {
+		return domainSearch{}, err
+	}
+
+	return answer.Search, nil
+}
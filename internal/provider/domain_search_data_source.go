@@ -0,0 +1,222 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &DomainSearchDataSource{}
+	_ datasource.DataSourceWithConfigure = &DomainSearchDataSource{}
+)
+
+// NewDomainSearchDataSource is a helper function to simplify the provider implementation.
+func NewDomainSearchDataSource() datasource.DataSource {
+	return &DomainSearchDataSource{}
+}
+
+// DomainSearchDataSource is the data source implementation.
+type DomainSearchDataSource struct {
+	client *piholeClient
+}
+
+// DomainSearchDataSourceModel maps the data source schema data.
+type DomainSearchDataSourceModel struct {
+	Domain    types.String             `tfsdk:"domain"`
+	Partial   types.Bool               `tfsdk:"partial"`
+	Blocked   types.Bool               `tfsdk:"blocked"`
+	BlockedBy types.String             `tfsdk:"blocked_by"`
+	Exact     []DomainSearchEntryModel `tfsdk:"exact"`
+	Regex     []DomainSearchEntryModel `tfsdk:"regex"`
+	Adlists   []DomainSearchListModel  `tfsdk:"adlists"`
+}
+
+// DomainSearchEntryModel maps an exact or regex entry of the domain lists.
+type DomainSearchEntryModel struct {
+	Domain  types.String `tfsdk:"domain"`
+	Type    types.String `tfsdk:"type"`
+	Enabled types.Bool   `tfsdk:"enabled"`
+	Comment types.String `tfsdk:"comment"`
+}
+
+// DomainSearchListModel maps an adlist holding the domain.
+type DomainSearchListModel struct {
+	Address types.String `tfsdk:"address"`
+	Domain  types.String `tfsdk:"domain"`
+	Type    types.String `tfsdk:"type"`
+	Enabled types.Bool   `tfsdk:"enabled"`
+}
+
+// Metadata returns the data source type name.
+func (d *DomainSearchDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_domain_search"
+}
+
+// Schema defines the schema for the data source.
+func (d *DomainSearchDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	entry := map[string]schema.Attribute{
+		"domain": schema.StringAttribute{
+			Description: "Domain, or regular expression, of the entry.",
+			Computed:    true,
+		},
+		"type": schema.StringAttribute{
+			Description: "Type of the entry, allow or deny.",
+			Computed:    true,
+		},
+		"enabled": schema.BoolAttribute{
+			Description: "Whether the entry is enabled.",
+			Computed:    true,
+		},
+		"comment": schema.StringAttribute{
+			Description: "Comment of the entry.",
+			Computed:    true,
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Entries of the Pihole domain lists and adlists matching a domain, telling why it is blocked. " +
+			"Requires Pihole v6.",
+		Attributes: map[string]schema.Attribute{
+			"domain": schema.StringAttribute{
+				Description: "Domain searched.",
+				Required:    true,
+				Validators: []validator.String{
+					hostnameValidator{},
+				},
+			},
+			"partial": schema.BoolAttribute{
+				Description: "Whether to also list the entries containing the domain, such as ads.example.com for " +
+					"example.com. Defaults to false.",
+				Optional: true,
+			},
+			"blocked": schema.BoolAttribute{
+				Description: "Whether the enabled entries block the domain, ignoring group assignments. Null with partial.",
+				Computed:    true,
+			},
+			"blocked_by": schema.StringAttribute{
+				Description: "What blocks the domain: exact, gravity or regex, or an empty string when it is not blocked. " +
+					"Null with partial.",
+				Computed: true,
+			},
+			"exact": schema.ListNestedAttribute{
+				Description: "Exact entries of the domain lists matching the domain.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: entry,
+				},
+			},
+			"regex": schema.ListNestedAttribute{
+				Description: "Regex entries of the domain lists matching the domain.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: entry,
+				},
+			},
+			"adlists": schema.ListNestedAttribute{
+				Description: "Adlists holding the domain.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"address": schema.StringAttribute{
+							Description: "Address of the adlist.",
+							Computed:    true,
+						},
+						"domain": schema.StringAttribute{
+							Description: "Domain held by the adlist.",
+							Computed:    true,
+						},
+						"type": schema.StringAttribute{
+							Description: "Type of the adlist, block or allow.",
+							Computed:    true,
+						},
+						"enabled": schema.BoolAttribute{
+							Description: "Whether the adlist is enabled.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *DomainSearchDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*piholeClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *piholeClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+// Read searches the lists for the domain.
+func (d *DomainSearchDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state DomainSearchDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	search, err := d.client.SearchDomain(ctx, state.Domain.ValueString(), state.Partial.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Searching Pihole Lists",
+			fmt.Sprintf("Could not search the lists for %s: %s", state.Domain.ValueString(), err),
+		)
+		return
+	}
+
+	state.Exact = []DomainSearchEntryModel{}
+	state.Regex = []DomainSearchEntryModel{}
+	for _, entry := range search.Domains {
+		model := DomainSearchEntryModel{
+			Domain:  types.StringValue(entry.Domain),
+			Type:    types.StringValue(entry.Type),
+			Enabled: types.BoolValue(entry.Enabled),
+			Comment: types.StringPointerValue(entry.Comment),
+		}
+		if entry.Kind == "regex" {
+			state.Regex = append(state.Regex, model)
+		} else {
+			state.Exact = append(state.Exact, model)
+		}
+	}
+
+	state.Adlists = []DomainSearchListModel{}
+	for _, entry := range search.Gravity {
+		state.Adlists = append(state.Adlists, DomainSearchListModel{
+			Address: types.StringValue(entry.Address),
+			Domain:  types.StringValue(entry.Domain),
+			Type:    types.StringValue(entry.Type),
+			Enabled: types.BoolValue(entry.Enabled),
+		})
+	}
+
+	// Partial searches match other domains, which tell nothing of this one
+	state.Blocked, state.BlockedBy = types.BoolNull(), types.StringNull()
+	if !state.Partial.ValueBool() {
+		blockedBy := search.BlockedBy()
+		state.Blocked, state.BlockedBy = types.BoolValue(blockedBy != ""), types.StringValue(blockedBy)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDomainSearchBlockedBy(t *testing.T) {
+	allow := domainSearchEntry{Domain: "ads.example.com", Type: "allow", Kind: "exact", Enabled: true}
+	deny := domainSearchEntry{Domain: "ads.example.com", Type: "deny", Kind: "exact", Enabled: true}
+	regex := domainSearchEntry{Domain: `(^|\.)example\.com$`, Type: "deny", Kind: "regex", Enabled: true}
+	allowRegex := domainSearchEntry{Domain: `^ads\.`, Type: "allow", Kind: "regex", Enabled: true}
+	block := domainSearchEntry{Domain: "ads.example.com", Address: "https://example.com/hosts", Type: "block", Enabled: true}
+	antigravity := domainSearchEntry{Domain: "ads.example.com", Address: "https://example.com/allow", Type: "allow", Enabled: true}
+	disabled := func(entry domainSearchEntry) domainSearchEntry {
+		entry.Enabled = false
+		return entry
+	}
+
+	tests := map[string]struct {
+		search    domainSearch
+		blockedBy string
+	}{
+		"none":               {domainSearch{}, ""},
+		"exact":              {domainSearch{Domains: []domainSearchEntry{deny, regex}, Gravity: []domainSearchEntry{block}}, "exact"},
+		"allowed":            {domainSearch{Domains: []domainSearchEntry{allow, deny}, Gravity: []domainSearchEntry{block}}, ""},
+		"allowed regex":      {domainSearch{Domains: []domainSearchEntry{allowRegex, regex}}, ""},
+		"gravity":            {domainSearch{Domains: []domainSearchEntry{regex}, Gravity: []domainSearchEntry{block}}, "gravity"},
+		"antigravity":        {domainSearch{Gravity: []domainSearchEntry{block, antigravity}}, ""},
+		"regex":              {domainSearch{Domains: []domainSearchEntry{regex}, Gravity: []domainSearchEntry{block, antigravity}}, "regex"},
+		"disabled":           {domainSearch{Domains: []domainSearchEntry{disabled(deny)}, Gravity: []domainSearchEntry{disabled(block)}}, ""},
+		"disabled allowlist": {domainSearch{Domains: []domainSearchEntry{disabled(allow), regex}}, "regex"},
+	}
+
+	for name, test := range tests {
+		if blockedBy := test.search.BlockedBy(); blockedBy != test.blockedBy {
+			t.Errorf("%s: expected %q, got %q", name, test.blockedBy, blockedBy)
+		}
+	}
+}
+
+func TestClientSearchDomain(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	fake.searches = map[string]domainSearch{
+		"ads.example.com": {
+			Domains: []domainSearchEntry{{Domain: `(^|\.)example\.com$`, Type: "deny", Kind: "regex", Enabled: true}},
+		},
+	}
+
+	search, err := fake.client().SearchDomain(ctx, "ads.example.com", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(search.Domains) != 1 || search.BlockedBy() != "regex" {
+		t.Fatalf("expected the domain to be blocked by the regex, got %+v", search)
+	}
+
+	if _, err := newFakePihole(t).client().SearchDomain(ctx, "ads.example.com", false); err == nil {
+		t.Fatal("expected an error on Pihole v5")
+	}
+}
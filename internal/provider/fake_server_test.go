@@ -0,0 +1,963 @@
+package provider
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Credentials accepted by the fake Pihole.
+const (
+	fakePiholePassword    = "fake-password"
+	fakePiholeAppPassword = "fake-app-password"
+	fakePiholeSID         = "fake-sid"
+	fakePiholeCSRF        = "fake-csrf"
+)
+
+// fakePiholeToken is the v5 API token derived from the fake password.
+var fakePiholeToken = v5Token(fakePiholePassword)
+
+// fakePihole emulates the custom DNS and CNAME endpoints of the Pihole v5
+// API, or of the v6 API when v6 is set.
+type fakePihole struct {
+	*httptest.Server
+
+	v6 bool
+
+	// version is the Pihole version reported, v5.18.2 or v6.0 by default.
+	version string
+
+	mu    sync.Mutex
+	dns   [][]string
+	cname [][]string
+
+	// calls counts the requests received by list and action, such as
+	// "customdns/get" or "customcname/add", whatever the API version.
+	calls map[string]int
+
+	// config holds the v6 configuration other than the custom DNS and CNAME
+	// records, by dotted key such as misc.dnsmasq_lines.
+	config map[string]interface{}
+
+	// teleporter is the Teleporter archive served by the v6 API, replaced by
+	// restores, whose selection is kept in teleporterImport.
+	teleporter       []byte
+	teleporterImport teleporterImport
+
+	// queries is the query log, oldest first.
+	queries []piholeQuery
+
+	// searches are the matches of the domains searched, by domain.
+	searches map[string]domainSearch
+
+	// groups and items are the gravity database, items holding the adlists,
+	// domains and clients by table.
+	groups []group
+	items  map[string][]listItem
+
+	// writeDelay, when set, makes adds rewrite the whole list after the
+	// delay, as Pihole does with custom.list, so concurrent adds are lost.
+	writeDelay time.Duration
+
+	// copied, when set, runs once an add has copied the list it rewrites,
+	// letting tests hold the add until other adds copied the list too.
+	copied func()
+
+	// intercept, when set, runs before each request is handled. It answers
+	// the request itself by returning true.
+	intercept func(w http.ResponseWriter, r *http.Request) bool
+}
+
+// newFakePihole starts a fake Pihole v5 closed at the end of the test.
+func newFakePihole(t testing.TB) *fakePihole {
+	f := &fakePihole{
+		calls: map[string]int{},
+		config: map[string]interface{}{
+			"misc.dnsmasq_lines":        []interface{}{},
+			"dns.domain":                "lan",
+			"dns.expandHosts":           false,
+			"dns.domainNeeded":          false,
+			"dns.bogusPriv":             true,
+			"dns.rateLimit.count":       1000,
+			"dns.rateLimit.interval":    60,
+			"dns.queryLogging":          true,
+			"misc.privacylevel":         0,
+			"database.maxDBdays":        91,
+			"dns.upstreams":             []interface{}{"8.8.8.8"},
+			"webserver.session.timeout": 1800,
+		},
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+// newFakePiholeV6 starts a fake Pihole v6 closed at the end of the test.
+func newFakePiholeV6(t testing.TB) *fakePihole {
+	f := newFakePihole(t)
+	f.v6 = true
+
+	return f
+}
+
+// APIURL returns the API endpoint of the fake Pihole.
+func (f *fakePihole) APIURL() string {
+	if f.v6 {
+		return f.Server.URL + "/api"
+	}
+
+	return f.Server.URL + "/admin/api.php"
+}
+
+// client returns a provider client configured for the fake Pihole.
+func (f *fakePihole) client() *piholeClient {
+	httpClient, _ := newHTTPClient(transportConfig{})
+	httpClient = newRetryClient(httpClient, defaultMaxRetries)
+
+	if f.v6 {
+		return newPiholeClient(newV6API(f.APIURL(), fakePiholePassword, httpClient))
+	}
+
+	return newPiholeClient(newV5API(f.APIURL(), fakePiholeToken, httpClient))
+}
+
+// count returns the number of requests received for the given list and action.
+func (f *fakePihole) count(call string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[call]
+}
+
+func (f *fakePihole) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case !f.v6 && r.URL.Path == "/admin/api.php":
+		f.serveV5(w, r)
+	case f.v6 && strings.HasPrefix(r.URL.Path, "/api/"):
+		f.serveV6(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakePihole) serveV5(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	list := "customdns"
+	if query.Has("customcname") {
+		list = "customcname"
+	}
+	action := query.Get("action")
+
+	if f.intercepted(w, r, list, action) {
+		return
+	}
+
+	if query.Has("versions") {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"core_current": f.reportedVersion("v5.18.2")})
+		return
+	}
+
+	// The v5 API answers unauthenticated calls with an empty array
+	if query.Get("auth") != fakePiholeToken {
+		_, _ = w.Write([]byte("[]"))
+		return
+	}
+
+	if query.Has("status") {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "enabled"})
+		return
+	}
+	if query.Has("getAllQueries") {
+		f.serveV5Queries(w, query)
+		return
+	}
+	if query.Has("topItems") || query.Has("topClients") || query.Has("getForwardDestinations") || query.Has("summaryRaw") {
+		f.serveV5Stats(w, query)
+		return
+	}
+
+	value := query.Get("ip")
+	if list == "customcname" {
+		value = query.Get("target")
+	}
+
+	res := f.update(list, action, query.Get("domain"), value)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (f *fakePihole) serveV6(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api")
+
+	if path == "/auth" {
+		f.serveV6Auth(w, r)
+		return
+	}
+
+	list, item := "", ""
+	switch {
+	case strings.HasPrefix(path, "/config/dns/hosts"):
+		list, item = "customdns", strings.TrimPrefix(path, "/config/dns/hosts")
+	case strings.HasPrefix(path, "/config/dns/cnameRecords"):
+		list, item = "customcname", strings.TrimPrefix(path, "/config/dns/cnameRecords")
+	case path == "/teleporter":
+		list = "teleporter"
+	case path == "/config":
+		list = "config"
+	case strings.HasPrefix(path, "/config/"):
+		list, item = f.configKey(strings.TrimPrefix(path, "/config/"))
+	case path == "/groups":
+		list = "groups"
+	case path == "/action/gravity":
+		list = "gravity"
+	case strings.HasPrefix(path, "/lists"), strings.HasPrefix(path, "/domains"), strings.HasPrefix(path, "/clients"):
+		list, item = path[1:], ""
+		if i := strings.IndexAny(list, "/:"); i >= 0 {
+			list, item = list[:i], list[i:]
+		}
+	}
+	action := map[string]string{
+		http.MethodGet:    "get",
+		http.MethodPut:    "add",
+		http.MethodPost:   "add",
+		http.MethodPatch:  "set",
+		http.MethodDelete: "delete",
+	}[r.Method]
+	switch {
+	case r.Method == http.MethodPut && (list == listsTable || list == domainsTable || list == clientsTable):
+		// Items of the gravity database are replaced with PUT
+		action = "set"
+	case strings.HasSuffix(item, ":batchDelete"):
+		action = "delete"
+	case list == "gravity":
+		action = "run"
+	}
+
+	if f.intercepted(w, r, list, action) {
+		return
+	}
+
+	if r.Header.Get("X-FTL-SID") != fakePiholeSID {
+		writeV6Error(w, http.StatusUnauthorized, "unauthorized", "Unauthorized")
+		return
+	}
+	if list == "teleporter" {
+		f.serveV6Teleporter(w, r)
+		return
+	}
+	if list != "customdns" && list != "customcname" && strings.HasPrefix(path, "/config") {
+		f.serveV6Config(w, r, list, item)
+		return
+	}
+	if path == "/info/version" {
+		version := map[string]interface{}{"local": map[string]interface{}{"version": f.reportedVersion("v6.0")}}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"version": map[string]interface{}{"core": version, "ftl": version},
+		})
+		return
+	}
+	if path == "/queries" {
+		f.serveV6Queries(w, r.URL.Query())
+		return
+	}
+	if strings.HasPrefix(path, "/search/") {
+		f.mu.Lock()
+		domain, _ := url.PathUnescape(strings.TrimPrefix(path, "/search/"))
+		search := f.searches[domain]
+		f.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"search": search})
+		return
+	}
+	if list == "gravity" {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "  [i] Neutrino emissions detected...\n  [✓] Done.\n")
+		return
+	}
+	if list == "groups" || list == listsTable || list == domainsTable || list == clientsTable {
+		f.serveV6Items(w, r, list, item)
+		return
+	}
+	if strings.HasPrefix(path, "/stats/") {
+		f.serveV6Stats(w, path, r.URL.Query())
+		return
+	}
+	if list == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Items are "ip domain" hosts and "domain,target" CNAMEs
+	item, _ = url.PathUnescape(strings.TrimPrefix(item, "/"))
+	var domain, value string
+	if fields := strings.Fields(item); list == "customdns" && len(fields) == 2 {
+		domain, value = fields[1], fields[0]
+	}
+	if fields := strings.Split(item, ","); list == "customcname" && len(fields) == 2 {
+		domain, value = fields[0], fields[1]
+	}
+
+	res := f.update(list, action, domain, value).(map[string]interface{})
+	switch {
+	case action == "get":
+		var items []string
+		for _, record := range res["data"].([][]string) {
+			if list == "customdns" {
+				items = append(items, record[1]+" "+record[0])
+			} else {
+				items = append(items, record[0]+","+record[1])
+			}
+		}
+		key := map[string]string{"customdns": "hosts", "customcname": "cnameRecords"}[list]
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"config": map[string]interface{}{"dns": map[string]interface{}{key: items}},
+		})
+	case !res["success"].(bool) && action == "add":
+		writeV6Error(w, http.StatusBadRequest, "bad_request", "Item already present")
+	case !res["success"].(bool):
+		writeV6Error(w, http.StatusNotFound, "not_found", "Item not found")
+	case action == "add":
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *fakePihole) serveV6Auth(w http.ResponseWriter, r *http.Request) {
+	valid := r.Header.Get("X-FTL-SID") == fakePiholeSID
+
+	if r.Method == http.MethodPost {
+		if f.intercepted(w, r, "auth", "login") {
+			return
+		}
+		var login struct {
+			Password string `json:"password"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&login)
+		valid = login.Password == fakePiholePassword || login.Password == fakePiholeAppPassword
+	}
+
+	if r.Method == http.MethodDelete {
+		if !valid {
+			writeV6Error(w, http.StatusUnauthorized, "unauthorized", "Unauthorized")
+			return
+		}
+		f.mu.Lock()
+		f.calls["auth/delete"]++
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	session := map[string]interface{}{"valid": false, "sid": nil, "validity": -1, "message": "password incorrect"}
+	status := http.StatusUnauthorized
+	if valid {
+		session = map[string]interface{}{"valid": true, "sid": fakePiholeSID, "csrf": fakePiholeCSRF, "validity": 1800, "message": "correct password"}
+		status = http.StatusOK
+	}
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"session": session})
+}
+
+// configKey splits a configuration path into the dotted key known to the fake
+// Pihole and the item of an array key.
+func (f *fakePihole) configKey(configPath string) (string, string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(configPath, "/")
+	for i := len(parts); i > 0; i-- {
+		key := strings.Join(parts[:i], ".")
+		if _, ok := f.config[key]; ok {
+			return key, strings.Join(parts[i:], "/")
+		}
+	}
+
+	return strings.Join(parts, "."), ""
+}
+
+// serveV6Config serves the configuration keys held in config: sections and
+// keys are read with GET, array items added with PUT and removed with DELETE,
+// and values set with PATCH on /config.
+func (f *fakePihole) serveV6Config(w http.ResponseWriter, r *http.Request, key string, item string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method == http.MethodPatch {
+		var body struct {
+			Config map[string]interface{} `json:"config"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || !f.patchConfig("", body.Config) {
+			writeV6Error(w, http.StatusBadRequest, "bad_request", "Config item is invalid")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"config": body.Config})
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		// Nest the values under each part of their key
+		answer := map[string]interface{}{}
+		for name, value := range f.config {
+			if name != key && !strings.HasPrefix(name, key+".") {
+				continue
+			}
+			level := answer
+			parts := strings.Split(name, ".")
+			for _, part := range parts[:len(parts)-1] {
+				if _, ok := level[part]; !ok {
+					level[part] = map[string]interface{}{}
+				}
+				level = level[part].(map[string]interface{})
+			}
+			level[parts[len(parts)-1]] = value
+		}
+		if len(answer) == 0 {
+			writeV6Error(w, http.StatusBadRequest, "bad_request", "Config item is invalid")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"config": answer})
+		return
+	}
+
+	items, ok := f.config[key].([]interface{})
+	if !ok {
+		writeV6Error(w, http.StatusBadRequest, "bad_request", "Config item is not an array")
+		return
+	}
+	item, _ = url.PathUnescape(item)
+
+	switch r.Method {
+	case http.MethodPut:
+		for _, existing := range items {
+			if existing == item {
+				writeV6Error(w, http.StatusBadRequest, "bad_request", "Item already present")
+				return
+			}
+		}
+		f.config[key] = append(items, item)
+		w.WriteHeader(http.StatusCreated)
+
+	case http.MethodDelete:
+		for i, existing := range items {
+			if existing == item {
+				f.config[key] = append(items[:i:i], items[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		writeV6Error(w, http.StatusNotFound, "not_found", "Item not found")
+	}
+}
+
+// patchConfig sets the known keys found in the nested values, the caller
+// holding the lock. It reports whether all the keys are known.
+func (f *fakePihole) patchConfig(prefix string, values map[string]interface{}) bool {
+	for name, value := range values {
+		key := prefix + name
+		if _, ok := f.config[key]; ok {
+			f.config[key] = value
+			continue
+		}
+		nested, ok := value.(map[string]interface{})
+		if !ok || !f.patchConfig(key+".", nested) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// serveV5Queries serves the query log between from and until, of a client or
+// of a domain, oldest first, with the status numbers of the v5 API.
+func (f *fakePihole) serveV5Queries(w http.ResponseWriter, query url.Values) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// Calls are counted by server-side filter
+	filter := "all"
+	for _, param := range []string{"from", "client", "domain"} {
+		if query.Has(param) {
+			filter = param
+			break
+		}
+	}
+	f.calls["queries/"+filter]++
+
+	from, _ := strconv.ParseInt(query.Get("from"), 10, 64)
+	until, err := strconv.ParseInt(query.Get("until"), 10, 64)
+	if err != nil {
+		until = math.MaxInt64
+	}
+	client, domain := query.Get("client"), query.Get("domain")
+
+	data := [][]interface{}{}
+	for _, q := range f.queries {
+		if q.Time.Unix() < from || q.Time.Unix() > until {
+			continue
+		}
+		if client != "" && client != q.Client && client != q.ClientName {
+			continue
+		}
+		if client != "" && query.Get("type") == "blocked" && queryStatus(q.Detail) != "blocked" {
+			continue
+		}
+		if domain != "" && !strings.EqualFold(domain, q.Domain) {
+			continue
+		}
+		// The v5 API reports the name of the client when known
+		client := q.Client
+		if q.ClientName != "" {
+			client = q.ClientName
+		}
+		status := 0
+		for i, name := range queryStatusNames {
+			if name == q.Detail {
+				status = i
+			}
+		}
+		data = append(data, []interface{}{
+			strconv.FormatInt(q.Time.Unix(), 10), q.Type, q.Domain, client, strconv.Itoa(status),
+			"0", "4", "55", "", "-1", q.Upstream,
+		})
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+// serveV6Queries serves the page of the query log between from and until
+// starting at start, newest first, of the client, domain, type and detailed
+// status given.
+func (f *fakePihole) serveV6Queries(w http.ResponseWriter, query url.Values) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// Calls are counted by server-side filters
+	var filters []string
+	for _, param := range []string{"client_ip", "client_name", "domain", "type", "status"} {
+		if query.Has(param) {
+			filters = append(filters, param)
+		}
+	}
+	if len(filters) == 0 {
+		filters = append(filters, "all")
+	}
+	f.calls["queries/"+strings.Join(filters, "+")]++
+
+	from, _ := strconv.ParseInt(query.Get("from"), 10, 64)
+	until, err := strconv.ParseInt(query.Get("until"), 10, 64)
+	if err != nil {
+		until = math.MaxInt64
+	}
+	start, _ := strconv.Atoi(query.Get("start"))
+	length, err := strconv.Atoi(query.Get("length"))
+	if err != nil {
+		length = 100
+	}
+
+	queries := []interface{}{}
+	for i := len(f.queries) - 1; i >= 0; i-- {
+		q := f.queries[i]
+		if q.Time.Unix() < from || q.Time.Unix() > until {
+			continue
+		}
+		if (query.Has("client_ip") && query.Get("client_ip") != q.Client) ||
+			(query.Has("client_name") && query.Get("client_name") != q.ClientName) {
+			continue
+		}
+		if matched, _ := path.Match(query.Get("domain"), q.Domain); query.Has("domain") && !matched {
+			continue
+		}
+		if (query.Has("type") && query.Get("type") != q.Type) || (query.Has("status") && query.Get("status") != q.Detail) {
+			continue
+		}
+		queries = append(queries, map[string]interface{}{
+			"time":     float64(q.Time.UnixNano()) / float64(time.Second),
+			"type":     q.Type,
+			"domain":   q.Domain,
+			"status":   q.Detail,
+			"client":   map[string]interface{}{"ip": q.Client, "name": q.ClientName},
+			"upstream": q.Upstream,
+		})
+	}
+	total := len(queries)
+	if start > len(queries) {
+		start = len(queries)
+	}
+	queries = queries[start:]
+	if length < len(queries) {
+		queries = queries[:length]
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"queries": queries, "cursor": len(f.queries), "recordsTotal": total, "recordsFiltered": total,
+	})
+}
+
+// stats counts the queries of the query log by kind of top items and by
+// domain, "name|ip" client or "ip#port" upstream server.
+func (f *fakePihole) stats() map[string]map[string]int64 {
+	stats := map[string]map[string]int64{topDomains: {}, topBlockedDomains: {}, topClients: {}, topUpstreams: {}}
+	for _, q := range f.queries {
+		stats[topClients][q.ClientName+"|"+q.Client]++
+		switch queryStatus(q.Detail) {
+		case "blocked":
+			stats[topBlockedDomains][q.Domain]++
+			stats[topUpstreams]["blocklist"]++
+		case "cached":
+			stats[topDomains][q.Domain]++
+			stats[topUpstreams]["cache"]++
+		default:
+			stats[topDomains][q.Domain]++
+			stats[topUpstreams][q.Upstream]++
+		}
+	}
+
+	return stats
+}
+
+// top returns the count names with the most queries.
+func top(counts map[string]int64, count int) map[string]int64 {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return counts[names[i]] > counts[names[j]] })
+
+	selected := map[string]int64{}
+	for i := 0; i < len(names) && i < count; i++ {
+		selected[names[i]] = counts[names[i]]
+	}
+
+	return selected
+}
+
+// serveV5Stats serves the top items and summary of the v5 API, whose
+// sources and destinations are "name|ip" or a bare IP.
+func (f *fakePihole) serveV5Stats(w http.ResponseWriter, query url.Values) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stats := f.stats()
+	count := func(name string) int {
+		n, err := strconv.Atoi(query.Get(name))
+		if err != nil {
+			return 10
+		}
+		return n
+	}
+
+	answer := map[string]interface{}{}
+	switch {
+	case query.Has("topItems"):
+		answer["top_queries"] = top(stats[topDomains], count("topItems"))
+		answer["top_ads"] = top(stats[topBlockedDomains], count("topItems"))
+	case query.Has("topClients"):
+		sources := map[string]int64{}
+		for client, queries := range top(stats[topClients], count("topClients")) {
+			sources[strings.TrimPrefix(client, "|")] = queries
+		}
+		answer["top_sources"] = sources
+	case query.Has("getForwardDestinations"):
+		destinations := map[string]float64{}
+		for upstream, queries := range stats[topUpstreams] {
+			name := map[string]string{"blocklist": "blocked|blocked", "cache": "cached|cached"}[upstream]
+			if name == "" {
+				name = upstream + "|" + upstream
+			}
+			destinations[name] = math.Round(float64(queries)*10000/float64(len(f.queries))) / 100
+		}
+		answer["forward_destinations"] = destinations
+	default:
+		answer["dns_queries_today"] = len(f.queries)
+	}
+
+	_ = json.NewEncoder(w).Encode(answer)
+}
+
+// serveV6Stats serves the top domains, clients and upstreams of the v6 API.
+func (f *fakePihole) serveV6Stats(w http.ResponseWriter, path string, query url.Values) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stats := f.stats()
+	count, err := strconv.Atoi(query.Get("count"))
+	if err != nil {
+		count = 10
+	}
+
+	var items []map[string]interface{}
+	var key string
+	switch path {
+	case "/stats/top_domains":
+		kind := topDomains
+		if query.Get("blocked") == "true" {
+			kind = topBlockedDomains
+		}
+		for domain, queries := range top(stats[kind], count) {
+			items = append(items, map[string]interface{}{"domain": domain, "count": queries})
+		}
+		key = "domains"
+	case "/stats/top_clients":
+		for client, queries := range top(stats[topClients], count) {
+			name, ip, _ := strings.Cut(client, "|")
+			items = append(items, map[string]interface{}{"ip": ip, "name": name, "count": queries})
+		}
+		key = "clients"
+	case "/stats/upstreams":
+		for upstream, queries := range stats[topUpstreams] {
+			ip, port, found := strings.Cut(upstream, "#")
+			number, _ := strconv.Atoi(port)
+			if !found {
+				number = -1
+			}
+			items = append(items, map[string]interface{}{"ip": ip, "name": ip, "port": number, "count": queries})
+		}
+		key = "upstreams"
+	default:
+		writeV6Error(w, http.StatusNotFound, "not_found", "Not found")
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{key: items})
+}
+
+// serveV6Items serves the groups and the items of the gravity database, whose
+// groups are replaced with PUT.
+func (f *fakePihole) serveV6Items(w http.ResponseWriter, r *http.Request, table string, item string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if table == "groups" {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"groups": append([]group{}, f.groups...)})
+		return
+	}
+
+	if r.Method == http.MethodGet && item == "" {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{table: append([]listItem{}, f.items[table]...)})
+		return
+	}
+
+	if r.Method == http.MethodPost && table == listsTable {
+		f.addOrDeleteLists(w, r, item)
+		return
+	}
+
+	if r.Method == http.MethodPut {
+		var body struct {
+			Groups []int64 `json:"groups"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeV6Error(w, http.StatusBadRequest, "bad_request", "Invalid JSON")
+			return
+		}
+
+		for i, existing := range f.items[table] {
+			key := map[string]string{
+				listsTable:   "/" + existing.Address,
+				domainsTable: "/" + existing.Type + "/" + existing.Kind + "/" + existing.Domain,
+				clientsTable: "/" + existing.Client,
+			}[table]
+			if key == item && (table != listsTable || r.URL.Query().Get("type") == existing.Type) {
+				f.items[table][i].Groups = body.Groups
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{table: []listItem{f.items[table][i]}})
+				return
+			}
+		}
+	}
+
+	writeV6Error(w, http.StatusNotFound, "not_found", "Item not found")
+}
+
+// addOrDeleteLists adds the adlists of a POST to /lists, or removes the ones
+// of a POST to /lists:batchDelete. The lock is held by the caller.
+func (f *fakePihole) addOrDeleteLists(w http.ResponseWriter, r *http.Request, item string) {
+	if f.items == nil {
+		f.items = map[string][]listItem{}
+	}
+
+	if item == ":batchDelete" {
+		var batch []struct {
+			Item string `json:"item"`
+			Type string `json:"type"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			writeV6Error(w, http.StatusBadRequest, "bad_request", "Invalid JSON")
+			return
+		}
+		lists := f.items[listsTable][:0]
+		for _, list := range f.items[listsTable] {
+			deleted := false
+			for _, entry := range batch {
+				deleted = deleted || (entry.Item == list.Address && entry.Type == list.Type)
+			}
+			if !deleted {
+				lists = append(lists, list)
+			}
+		}
+		f.items[listsTable] = lists
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var body struct {
+		Address []string `json:"address"`
+		Comment *string  `json:"comment"`
+		Enabled *bool    `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeV6Error(w, http.StatusBadRequest, "bad_request", "Invalid JSON")
+		return
+	}
+
+	listType := r.URL.Query().Get("type")
+	type processed struct {
+		Item  string `json:"item"`
+		Error string `json:"error,omitempty"`
+	}
+	var success, failures []processed
+	for _, address := range body.Address {
+		exists := false
+		for _, list := range f.items[listsTable] {
+			exists = exists || (list.Address == address && list.Type == listType)
+		}
+		if exists {
+			failures = append(failures, processed{Item: address, Error: "UNIQUE constraint failed: adlist.address, adlist.type"})
+			continue
+		}
+
+		id := int64(1)
+		for _, list := range f.items[listsTable] {
+			if list.ID >= id {
+				id = list.ID + 1
+			}
+		}
+		f.items[listsTable] = append(f.items[listsTable], listItem{
+			ID: id, Address: address, Type: listType, Comment: body.Comment, Enabled: body.Enabled, Groups: []int64{0},
+		})
+		success = append(success, processed{Item: address})
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"processed": map[string]interface{}{"success": success, "errors": failures},
+	})
+}
+
+func (f *fakePihole) serveV6Teleporter(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Method == http.MethodGet {
+		w.Header().Set("Content-Type", "application/zip")
+		_, _ = w.Write(f.teleporter)
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		writeV6Error(w, http.StatusBadRequest, "bad_request", "No file uploaded")
+		return
+	}
+	defer file.Close()
+	archive, _ := io.ReadAll(file)
+
+	f.teleporter = archive
+	f.teleporterImport = teleporterImport{}
+	_ = json.Unmarshal([]byte(r.FormValue("import")), &f.teleporterImport)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"files": []string{"etc/pihole/pihole.toml"}, "took": 0.1})
+}
+
+// reportedVersion returns the version the fake Pihole reports, or fallback.
+func (f *fakePihole) reportedVersion(fallback string) string {
+	if f.version != "" {
+		return f.version
+	}
+
+	return fallback
+}
+
+// writeV6Error answers with an error in the format of the v6 API.
+func writeV6Error(w http.ResponseWriter, status int, key string, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{"key": key, "message": message, "hint": nil},
+	})
+}
+
+// intercepted counts the request, then runs intercept if set.
+func (f *fakePihole) intercepted(w http.ResponseWriter, r *http.Request, list string, action string) bool {
+	f.mu.Lock()
+	f.calls[list+"/"+action]++
+	f.mu.Unlock()
+
+	return f.intercept != nil && f.intercept(w, r)
+}
+
+// update runs the action against the list of records.
+func (f *fakePihole) update(list string, action string, domain string, value string) interface{} {
+	f.mu.Lock()
+	records := &f.dns
+	if list == "customcname" {
+		records = &f.cname
+	}
+
+	// Work on a copy of the list written back after the delay
+	if action == "add" && (f.writeDelay > 0 || f.copied != nil) {
+		snapshot := append([][]string{}, *records...)
+		f.mu.Unlock()
+		if f.copied != nil {
+			f.copied()
+		}
+		time.Sleep(f.writeDelay)
+		res := f.apply(&snapshot, action, domain, value)
+		f.mu.Lock()
+		*records = snapshot
+		f.mu.Unlock()
+
+		return res
+	}
+
+	defer f.mu.Unlock()
+
+	return f.apply(records, action, domain, value)
+}
+
+// apply runs the action against the records, the caller holding the lock.
+func (f *fakePihole) apply(records *[][]string, action string, domain string, value string) interface{} {
+	switch action {
+	case "get":
+		data := make([][]string, len(*records))
+		copy(data, *records)
+		return map[string]interface{}{"data": data}
+
+	case "add":
+		for _, item := range *records {
+			if strings.EqualFold(item[0], domain) {
+				return map[string]interface{}{"success": false, "message": "This domain already has a custom DNS entry"}
+			}
+		}
+		*records = append(*records, []string{domain, value})
+		return map[string]interface{}{"success": true, "message": ""}
+
+	case "delete":
+		for i, item := range *records {
+			if strings.EqualFold(item[0], domain) && item[1] == value {
+				*records = append((*records)[:i], (*records)[i+1:]...)
+				return map[string]interface{}{"success": true, "message": ""}
+			}
+		}
+		return map[string]interface{}{"success": false, "message": "This domain/ip association does not exist"}
+	}
+
+	return map[string]interface{}{"success": false, "message": "Unknown action"}
+}
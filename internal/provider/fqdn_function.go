@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &FqdnFunction{}
+
+// NewFqdnFunction is a helper function to simplify the provider implementation.
+func NewFqdnFunction() function.Function {
+	return &FqdnFunction{}
+}
+
+// FqdnFunction is the function implementation.
+type FqdnFunction struct{}
+
+// Metadata returns the function name.
+func (f *FqdnFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "fqdn"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *FqdnFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Qualifies a short host name with a domain",
+		Description: "Returns host followed by domain when host is a short host name, without dots, as Pihole does " +
+			"with expand_hosts. Names with dots are returned unchanged, without their trailing dot.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "host",
+				Description: "Short host name, such as nas, or fully qualified domain name.",
+			},
+			function.StringParameter{
+				Name:        "domain",
+				Description: "Domain appended to short host names, such as lan.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run qualifies the host name.
+func (f *FqdnFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var host, domain string
+	resp.Error = req.Arguments.Get(ctx, &host, &domain)
+	if resp.Error != nil {
+		return
+	}
+
+	fqdn := qualifyHostname(host, domain)
+	if !isValidHostname(fqdn) {
+		resp.Error = function.NewFuncError(fqdn + " is not a valid RFC 1123 hostname")
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, fqdn)
+}
@@ -0,0 +1,324 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &FtlSettingsResource{}
+	_ resource.ResourceWithConfigure   = &FtlSettingsResource{}
+	_ resource.ResourceWithImportState = &FtlSettingsResource{}
+)
+
+// ftlSettingsDefaults are the settings of a new Pihole, restored for the
+// managed settings when the resource is destroyed.
+var ftlSettingsDefaults = ftlSettings{
+	RateLimitCount:    1000,
+	RateLimitInterval: 60,
+	PrivacyLevel:      0,
+	QueryLogging:      true,
+	MaxDBDays:         91,
+}
+
+// ftlSettings are the operational settings of FTL, spread over the dns, misc
+// and database configuration sections.
+type ftlSettings struct {
+	RateLimitCount    int64
+	RateLimitInterval int64
+	PrivacyLevel      int64
+	QueryLogging      bool
+	MaxDBDays         int64
+}
+
+// getFTLSettings reads the operational settings of FTL.
+func getFTLSettings(ctx context.Context, client *piholeClient) (ftlSettings, error) {
+	var dns struct {
+		RateLimit struct {
+			Count    int64 `json:"count"`
+			Interval int64 `json:"interval"`
+		} `json:"rateLimit"`
+		QueryLogging bool `json:"queryLogging"`
+	}
+	var misc struct {
+		PrivacyLevel int64 `json:"privacylevel"`
+	}
+	var database struct {
+		MaxDBDays int64 `json:"maxDBdays"`
+	}
+
+	for key, out := range map[string]interface{}{"dns": &dns, "misc": &misc, "database": &database} {
+		if err := client.GetConfig(ctx, "pihole_ftl_settings", key, out); err != nil {
+			return ftlSettings{}, err
+		}
+	}
+
+	return ftlSettings{
+		RateLimitCount:    dns.RateLimit.Count,
+		RateLimitInterval: dns.RateLimit.Interval,
+		PrivacyLevel:      misc.PrivacyLevel,
+		QueryLogging:      dns.QueryLogging,
+		MaxDBDays:         database.MaxDBDays,
+	}, nil
+}
+
+// NewFtlSettingsResource is a helper function to simplify the provider implementation.
+func NewFtlSettingsResource() resource.Resource {
+	return &FtlSettingsResource{}
+}
+
+// FtlSettingsResource is the resource implementation.
+type FtlSettingsResource struct {
+	client *piholeClient
+}
+
+// FtlSettingsResourceModel maps the resource schema data.
+type FtlSettingsResourceModel struct {
+	LastUpdated       types.String `tfsdk:"last_updated"`
+	RateLimitCount    types.Int64  `tfsdk:"rate_limit_count"`
+	RateLimitInterval types.Int64  `tfsdk:"rate_limit_interval"`
+	PrivacyLevel      types.Int64  `tfsdk:"privacy_level"`
+	QueryLogging      types.Bool   `tfsdk:"query_logging"`
+	MaxDBDays         types.Int64  `tfsdk:"max_db_days"`
+}
+
+// Metadata returns the resource type name.
+func (r *FtlSettingsResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ftl_settings"
+}
+
+// Schema defines the schema for the resource.
+func (r *FtlSettingsResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Operational settings of the Pihole FTL engine: query rate limit, privacy level, query logging and " +
+			"database retention. Only the settings given are written and refreshed, the others being left to the Pihole " +
+			"web interface or to pihole_config. Destroying the resource puts the given settings back to the FTL defaults: " +
+			"1000 queries per 60 seconds, privacy level 0, query logging enabled and 91 days of history. Requires Pihole v6.",
+		Attributes: map[string]schema.Attribute{
+			"last_updated": schema.StringAttribute{
+				Description: "Timestamp of the last Terraform update of the settings.",
+				Computed:    true,
+			},
+			"rate_limit_count": schema.Int64Attribute{
+				Description: "Number of queries a client may send within rate_limit_interval before being rate limited, 0 disabling the rate limit.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64RangeValidator{min: 0, max: math.MaxUint32},
+				},
+			},
+			"rate_limit_interval": schema.Int64Attribute{
+				Description: "Interval of the rate limit, in seconds.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64RangeValidator{min: 1, max: math.MaxUint32},
+				},
+			},
+			"privacy_level": schema.Int64Attribute{
+				Description: "Privacy level, from 0 showing everything, 1 hiding domains, 2 hiding domains and clients, " +
+					"to 3 anonymizing everything.",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64RangeValidator{min: 0, max: 3},
+				},
+			},
+			"query_logging": schema.BoolAttribute{
+				Description: "Whether to log the queries.",
+				Optional:    true,
+			},
+			"max_db_days": schema.Int64Attribute{
+				Description: "Number of days the queries are kept in the long-term database, 0 disabling the database.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64RangeValidator{min: 0, max: math.MaxInt32},
+				},
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *FtlSettingsResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*piholeClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *piholeClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// apply writes the configured settings, then reads them back into model.
+func (r *FtlSettingsResource) apply(ctx context.Context, model *FtlSettingsResourceModel) error {
+	values := map[string]interface{}{}
+	for key, value := range map[string]types.Int64{
+		"dns.rateLimit.count":    model.RateLimitCount,
+		"dns.rateLimit.interval": model.RateLimitInterval,
+		"misc.privacylevel":      model.PrivacyLevel,
+		"database.maxDBdays":     model.MaxDBDays,
+	} {
+		if !value.IsNull() && !value.IsUnknown() {
+			values[key] = value.ValueInt64()
+		}
+	}
+	if !model.QueryLogging.IsNull() && !model.QueryLogging.IsUnknown() {
+		values["dns.queryLogging"] = model.QueryLogging.ValueBool()
+	}
+
+	if len(values) > 0 {
+		ctx = tflog.SetField(ctx, "settings", values)
+		if err := r.client.SetConfig(ctx, "pihole_ftl_settings", values); err != nil {
+			return err
+		}
+	}
+
+	settings, err := getFTLSettings(ctx, r.client)
+	if err != nil {
+		return err
+	}
+	model.setSettings(settings)
+	model.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+
+	return nil
+}
+
+// setSettings sets the managed settings of the model, those not null, to the
+// given settings.
+func (m *FtlSettingsResourceModel) setSettings(settings ftlSettings) {
+	for _, attribute := range []struct {
+		model *types.Int64
+		value int64
+	}{
+		{&m.RateLimitCount, settings.RateLimitCount},
+		{&m.RateLimitInterval, settings.RateLimitInterval},
+		{&m.PrivacyLevel, settings.PrivacyLevel},
+		{&m.MaxDBDays, settings.MaxDBDays},
+	} {
+		if !attribute.model.IsNull() {
+			*attribute.model = types.Int64Value(attribute.value)
+		}
+	}
+	if !m.QueryLogging.IsNull() {
+		m.QueryLogging = types.BoolValue(settings.QueryLogging)
+	}
+}
+
+// Create sets the FTL settings.
+func (r *FtlSettingsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan FtlSettingsResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.apply(ctx, &plan); err != nil {
+		resp.Diagnostics.AddError(
+			"Error setting FTL settings",
+			"Could not set the FTL settings, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read resource information.
+func (r *FtlSettingsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state FtlSettingsResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	settings, err := getFTLSettings(ctx, r.client)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Pihole FTL settings",
+			"Could not read the FTL settings: "+err.Error(),
+		)
+		return
+	}
+	state.setSettings(settings)
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update sets the changed FTL settings.
+func (r *FtlSettingsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan FtlSettingsResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.apply(ctx, &plan); err != nil {
+		resp.Diagnostics.AddError(
+			"Error updating FTL settings",
+			"Could not update the FTL settings, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete restores the Pihole defaults of the managed settings.
+func (r *FtlSettingsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var defaults FtlSettingsResourceModel
+	diags := req.State.Get(ctx, &defaults)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defaults.setSettings(ftlSettingsDefaults)
+
+	if err := r.apply(ctx, &defaults); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting FTL settings",
+			"Could not restore the default FTL settings, unexpected error: "+err.Error(),
+		)
+	}
+}
+
+// ImportState adopts the FTL settings whatever the import ID. No setting is
+// managed until the next apply writes the configured ones.
+func (r *FtlSettingsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	state := FtlSettingsResourceModel{
+		LastUpdated:       types.StringNull(),
+		RateLimitCount:    types.Int64Null(),
+		RateLimitInterval: types.Int64Null(),
+		PrivacyLevel:      types.Int64Null(),
+		QueryLogging:      types.BoolNull(),
+		MaxDBDays:         types.Int64Null(),
+	}
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
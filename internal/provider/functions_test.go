@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// runFunction runs a provider function with the given arguments.
+func runFunction(f function.Function, result attr.Value, arguments ...attr.Value) (attr.Value, *function.FuncError) {
+	resp := &function.RunResponse{Result: function.NewResultData(result)}
+	f.Run(context.Background(), function.RunRequest{Arguments: function.NewArgumentsData(arguments)}, resp)
+
+	return resp.Result.Value(), resp.Error
+}
+
+func TestFqdnFunction(t *testing.T) {
+	tests := map[[2]string]string{
+		{"nas", "lan"}:              "nas.lan",
+		{"nas", ".home.arpa."}:      "nas.home.arpa",
+		{"nas.example.com", "lan"}:  "nas.example.com",
+		{"nas.", "lan"}:             "nas",
+		{"nas", ""}:                 "nas",
+		{"www.example.com.", "lan"}: "www.example.com",
+	}
+	for arguments, expected := range tests {
+		result, err := runFunction(&FqdnFunction{}, types.StringUnknown(), types.StringValue(arguments[0]), types.StringValue(arguments[1]))
+		if err != nil || !result.Equal(types.StringValue(expected)) {
+			t.Errorf("%v: expected %s, got %v, %v", arguments, expected, result, err)
+		}
+	}
+
+	if _, err := runFunction(&FqdnFunction{}, types.StringUnknown(), types.StringValue("bad host"), types.StringValue("lan")); err == nil {
+		t.Error("expected an error for an invalid host name")
+	}
+}
+
+func TestReverseNameFunction(t *testing.T) {
+	result, err := runFunction(&ReverseNameFunction{}, types.StringUnknown(), types.StringValue("192.168.1.10"))
+	if err != nil || !result.Equal(types.StringValue("10.1.168.192.in-addr.arpa")) {
+		t.Fatalf("unexpected reverse name %v, %v", result, err)
+	}
+
+	if _, err := runFunction(&ReverseNameFunction{}, types.StringUnknown(), types.StringValue("nas.lan")); err == nil || err.FunctionArgument == nil {
+		t.Fatal("expected an argument error for an invalid IP address")
+	}
+}
+
+func TestIsValidHostnameFunction(t *testing.T) {
+	for s, valid := range map[string]bool{"nas.lan": true, "-nas.lan": false} {
+		result, err := runFunction(&IsValidHostnameFunction{}, types.BoolUnknown(), types.StringValue(s))
+		if err != nil || !result.Equal(types.BoolValue(valid)) {
+			t.Errorf("%s: expected %t, got %v, %v", s, valid, result, err)
+		}
+	}
+}
+
+func TestRegexForDomainFunction(t *testing.T) {
+	tests := map[string]struct {
+		domain     string
+		subdomains bool
+		expected   string
+	}{
+		"exact":      {"example.com", false, `^example\.com$`},
+		"subdomains": {"Ads-1.Example.com.", true, `(\.|^)ads-1\.example\.com$`},
+	}
+	for name, test := range tests {
+		result, err := runFunction(&RegexForDomainFunction{}, types.StringUnknown(), types.StringValue(test.domain), types.BoolValue(test.subdomains))
+		if err != nil || !result.Equal(types.StringValue(test.expected)) {
+			t.Errorf("%s: expected %s, got %v, %v", name, test.expected, result, err)
+		}
+	}
+
+	if _, err := runFunction(&RegexForDomainFunction{}, types.StringUnknown(), types.StringValue("*.example.com"), types.BoolValue(true)); err == nil {
+		t.Error("expected an error for an invalid domain")
+	}
+}
@@ -0,0 +1,303 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GenerateConfigSettings are the settings used to connect to the Pihole
+// whose configuration is generated.
+type GenerateConfigSettings struct {
+	// URL is the address of Pihole, as accepted by the url attribute.
+	URL string
+	// Token is the v5 API token, or a v6 application password.
+	Token string
+	// Password is the web interface password.
+	Password string
+}
+
+// GenerateConfig writes the resource blocks of the records found in Pihole,
+// along with the import blocks adopting them with Terraform 1.5 and later.
+//
+// Only the items with a matching resource type are generated: custom DNS
+// records and CNAME records, and with Pihole v6 the adlists and the members
+// of the groups. The others are listed in "# skipped:" comments.
+func GenerateConfig(ctx context.Context, w io.Writer, settings GenerateConfigSettings) error {
+	if settings.URL == "" {
+		return fmt.Errorf("missing Pihole URL, set PIHOLE_API_URL")
+	}
+	if settings.Token == "" && settings.Password == "" {
+		return fmt.Errorf("missing Pihole credentials, set PIHOLE_TOKEN or PIHOLE_PASSWORD")
+	}
+
+	httpClient, err := newHTTPClient(transportConfig{Timeout: defaultRequestTimeout})
+	if err != nil {
+		return err
+	}
+
+	ctx = maskSecrets(ctx, settings.Token, settings.Password)
+	api, err := newPiholeAPI(ctx, settings.URL, piholeCredentials{Token: settings.Token, Password: settings.Password}, newRetryClient(httpClient, defaultMaxRetries))
+	if err != nil {
+		return err
+	}
+	// Log the session out rather than leaving it to expire
+	if api, ok := api.(*v6API); ok {
+		defer func() { _ = api.logout(ctx) }()
+	}
+	if err := api.Check(ctx); err != nil {
+		return err
+	}
+
+	return newPiholeClient(api).generateConfig(ctx, w)
+}
+
+// generatedResource is a resource block to generate, with its import ID.
+type generatedResource struct {
+	Type     string
+	ImportID string
+	// Name is the source of the resource name, the import ID when empty.
+	Name string
+	// Attributes are the names and HCL expressions of the attributes.
+	Attributes [][2]string
+}
+
+// generateConfig writes the resource and import blocks of the records.
+func (c *piholeClient) generateConfig(ctx context.Context, w io.Writer) error {
+	var resources []generatedResource
+
+	dnsrecords, err := c.GetAllCustomDNS(ctx)
+	if err != nil {
+		return fmt.Errorf("listing custom DNS records: %w", err)
+	}
+	sort.Slice(dnsrecords, func(i, j int) bool {
+		if dnsrecords[i].Domain != dnsrecords[j].Domain {
+			return dnsrecords[i].Domain < dnsrecords[j].Domain
+		}
+		return dnsrecords[i].IP < dnsrecords[j].IP
+	})
+	// The IP address tells apart the records of a domain having several
+	records := map[string]int{}
+	for _, record := range dnsrecords {
+		records[strings.ToLower(record.Domain)]++
+	}
+	for _, record := range dnsrecords {
+		importID := record.Domain
+		if records[strings.ToLower(record.Domain)] > 1 {
+			importID += "," + record.IP
+		}
+		resources = append(resources, generatedResource{
+			Type:       "pihole_dnsrecord",
+			ImportID:   importID,
+			Attributes: [][2]string{{"domain", quoteHCL(record.Domain)}, {"ip", quoteHCL(record.IP)}},
+		})
+	}
+
+	cnamerecords, err := c.GetAllCustomCNAME(ctx)
+	if err != nil {
+		return fmt.Errorf("listing custom CNAME records: %w", err)
+	}
+	sort.Slice(cnamerecords, func(i, j int) bool { return cnamerecords[i].Domain < cnamerecords[j].Domain })
+	for _, record := range cnamerecords {
+		resources = append(resources, generatedResource{
+			Type:       "pihole_cname",
+			ImportID:   record.Domain,
+			Attributes: [][2]string{{"domain", quoteHCL(record.Domain)}, {"target", quoteHCL(record.Target)}},
+		})
+	}
+
+	var skipped []string
+	if c.api.Version() < 6 {
+		skipped = append(skipped, "adlists, domains, groups and clients, managed with Pihole v6 only")
+	} else {
+		gravity, notes, err := c.generateGravityConfig(ctx)
+		if err != nil {
+			return err
+		}
+		resources, skipped = append(resources, gravity...), notes
+	}
+
+	if err := writeGeneratedConfig(w, resources); err != nil {
+		return err
+	}
+	for i, note := range skipped {
+		if i == 0 && len(resources) > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "# skipped: %s\n", note); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generateGravityConfig returns the resources of the adlists and the group
+// members of Pihole v6, and notes on the items no resource type manages.
+func (c *piholeClient) generateGravityConfig(ctx context.Context) ([]generatedResource, []string, error) {
+	api, err := c.v6("Generating adlists and groups")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var resources []generatedResource
+	items := map[string][]listItem{}
+	for _, table := range groupTables {
+		if items[table], err = api.getItems(ctx, table); err != nil {
+			return nil, nil, fmt.Errorf("listing %s: %w", table, err)
+		}
+	}
+
+	for _, listType := range adlistTypes {
+		var urls []string
+		for _, item := range items[listsTable] {
+			if item.Type == listType {
+				urls = append(urls, quoteHCL(item.Address))
+			}
+		}
+		if len(urls) == 0 {
+			continue
+		}
+		sort.Strings(urls)
+		resources = append(resources, generatedResource{
+			Type:     "pihole_adlists",
+			ImportID: listType,
+			Name:     listType + "lists",
+			Attributes: [][2]string{
+				{"type", quoteHCL(listType)},
+				{"urls", "[\n    " + strings.Join(urls, ",\n    ") + ",\n  ]"},
+			},
+		})
+	}
+
+	groups, err := api.getGroups(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing groups: %w", err)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+	for _, group := range groups {
+		if group.ID == defaultGroupID {
+			continue
+		}
+
+		var attributes [][2]string
+		attributes = append(attributes, [2]string{"group_id", strconv.FormatInt(group.ID, 10)})
+		for _, table := range groupTables {
+			var ids []string
+			for _, item := range items[table] {
+				if item.hasGroup(group.ID) {
+					ids = append(ids, strconv.FormatInt(item.ID, 10))
+				}
+			}
+			if len(ids) > 0 {
+				attributes = append(attributes, [2]string{groupAssignmentAttributes[table], "[" + strings.Join(ids, ", ") + "]"})
+			}
+		}
+		resources = append(resources, generatedResource{
+			Type:       "pihole_group_assignment",
+			ImportID:   strconv.FormatInt(group.ID, 10),
+			Name:       group.Name,
+			Attributes: attributes,
+		})
+	}
+
+	notes := []string{"the members of the Default group, which pihole_group_assignment does not manage"}
+	for _, count := range []struct {
+		n    int
+		what string
+	}{
+		{len(groups), "groups themselves, no resource type creates them"},
+		{len(items[domainsTable]), "domain list entries, no resource type manages them"},
+		{len(items[clientsTable]), "clients themselves, no resource type creates them"},
+	} {
+		if count.n > 0 {
+			notes = append(notes, fmt.Sprintf("%d %s", count.n, count.what))
+		}
+	}
+
+	return resources, notes, nil
+}
+
+// writeGeneratedConfig writes the resources as HCL formatted as terraform fmt
+// does, each resource followed by its import block.
+func writeGeneratedConfig(w io.Writer, resources []generatedResource) error {
+	names := map[string]bool{}
+
+	for i, res := range resources {
+		source := res.Name
+		if source == "" {
+			source = res.ImportID
+		}
+		name := resourceName(source)
+		for n := 2; names[res.Type+"."+name]; n++ {
+			name = fmt.Sprintf("%s_%d", resourceName(source), n)
+		}
+		names[res.Type+"."+name] = true
+
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+
+		var block strings.Builder
+		fmt.Fprintf(&block, "resource %q %q {\n", res.Type, name)
+		writeAttributes(&block, res.Attributes)
+		fmt.Fprintf(&block, "}\n\nimport {\n")
+		writeAttributes(&block, [][2]string{{"to", res.Type + "." + name}, {"id", quoteHCL(res.ImportID)}})
+		fmt.Fprintf(&block, "}\n")
+
+		if _, err := io.WriteString(w, block.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeAttributes writes attributes with their equal signs aligned.
+func writeAttributes(w io.Writer, attributes [][2]string) {
+	width := 0
+	for _, attribute := range attributes {
+		if len(attribute[0]) > width {
+			width = len(attribute[0])
+		}
+	}
+
+	for _, attribute := range attributes {
+		fmt.Fprintf(w, "  %-*s = %s\n", width, attribute[0], attribute[1])
+	}
+}
+
+// quoteHCL returns s as a quoted HCL string, escaping template sequences.
+func quoteHCL(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "${", "$${")
+	s = strings.ReplaceAll(s, "%{", "%%{")
+
+	return `"` + s + `"`
+}
+
+// resourceName derives a Terraform resource name from a domain, such as
+// host_example_com for host.example.com.
+func resourceName(domain string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, domain)
+
+	if name == "" || !unicode.IsLetter(rune(name[0])) && name[0] != '_' {
+		name = "_" + name
+	}
+
+	return name
+}
@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGenerateConfig(t *testing.T) {
+	fake := newFakePihole(t)
+	fake.dns = [][]string{{"nas.lan", "192.168.1.20"}, {"1host.lan", "192.168.1.21"}, {"printer.lan", "192.168.1.31"}, {"printer.lan", "192.168.1.30"}}
+	fake.cname = [][]string{{"files.lan", "nas.lan"}}
+
+	var out bytes.Buffer
+	err := GenerateConfig(context.Background(), &out, GenerateConfigSettings{URL: fake.URL, Password: fakePiholePassword})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `resource "pihole_dnsrecord" "_1host_lan" {
+  domain = "1host.lan"
+  ip     = "192.168.1.21"
+}
+
+import {
+  to = pihole_dnsrecord._1host_lan
+  id = "1host.lan"
+}
+
+resource "pihole_dnsrecord" "nas_lan" {
+  domain = "nas.lan"
+  ip     = "192.168.1.20"
+}
+
+import {
+  to = pihole_dnsrecord.nas_lan
+  id = "nas.lan"
+}
+
+resource "pihole_dnsrecord" "printer_lan_192_168_1_30" {
+  domain = "printer.lan"
+  ip     = "192.168.1.30"
+}
+
+import {
+  to = pihole_dnsrecord.printer_lan_192_168_1_30
+  id = "printer.lan,192.168.1.30"
+}
+
+resource "pihole_dnsrecord" "printer_lan_192_168_1_31" {
+  domain = "printer.lan"
+  ip     = "192.168.1.31"
+}
+
+import {
+  to = pihole_dnsrecord.printer_lan_192_168_1_31
+  id = "printer.lan,192.168.1.31"
+}
+
+resource "pihole_cname" "files_lan" {
+  domain = "files.lan"
+  target = "nas.lan"
+}
+
+import {
+  to = pihole_cname.files_lan
+  id = "files.lan"
+}
+
+# skipped: adlists, domains, groups and clients, managed with Pihole v6 only
+`
+	if out.String() != expected {
+		t.Fatalf("unexpected configuration:\n%s", out.String())
+	}
+}
+
+func TestGenerateConfigLogsOut(t *testing.T) {
+	fake := newFakePiholeV6(t)
+
+	err := GenerateConfig(context.Background(), &bytes.Buffer{}, GenerateConfigSettings{URL: fake.URL, Password: fakePiholePassword})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls := fake.count("auth/delete"); calls != 1 {
+		t.Fatalf("expected the session to be logged out once, got %d", calls)
+	}
+}
+
+func TestGenerateConfigMissingSettings(t *testing.T) {
+	err := GenerateConfig(context.Background(), &bytes.Buffer{}, GenerateConfigSettings{URL: "http://pi.hole"})
+	if err == nil || !strings.Contains(err.Error(), "credentials") {
+		t.Fatalf("expected an error for the missing credentials, got: %v", err)
+	}
+}
+
+func TestWriteGeneratedConfigUniqueNames(t *testing.T) {
+	var out bytes.Buffer
+	err := writeGeneratedConfig(&out, []generatedResource{
+		{Type: "pihole_dnsrecord", ImportID: "a-b.lan", Attributes: [][2]string{{"domain", quoteHCL("a-b.lan")}}},
+		{Type: "pihole_dnsrecord", ImportID: "a_b.lan", Attributes: [][2]string{{"domain", quoteHCL("a_b.lan")}}},
+		{Type: "pihole_dnsrecord", ImportID: "A-B.lan", Attributes: [][2]string{{"domain", quoteHCL("A-B.lan")}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"pihole_dnsrecord.a-b_lan", "pihole_dnsrecord.a_b_lan", "pihole_dnsrecord.a-b_lan_2"} {
+		if !strings.Contains(out.String(), "to = "+name+"\n") {
+			t.Errorf("expected the resource %s, got:\n%s", name, out.String())
+		}
+	}
+}
+
+func TestQuoteHCL(t *testing.T) {
+	if got := quoteHCL(`a"${b}\`); got != `"a\"$${b}\\"` {
+		t.Fatalf("unexpected quoted string %s", got)
+	}
+}
+
+func TestGenerateConfigV6(t *testing.T) {
+	fake := newFakePiholeV6(t)
+	fakeGravity(fake)
+	fake.dns = [][]string{{"nas.lan", "192.168.1.20"}}
+
+	var out bytes.Buffer
+	if err := fake.client().generateConfig(context.Background(), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"resource \"pihole_dnsrecord\" \"nas_lan\" {\n",
+		"resource \"pihole_adlists\" \"blocklists\" {\n  type = \"block\"\n  urls = [\n    \"https://example.com/hosts\",\n    \"https://example.org/hosts\",\n  ]\n}\n",
+		"import {\n  to = pihole_adlists.blocklists\n  id = \"block\"\n}\n",
+		"resource \"pihole_group_assignment\" \"kids\" {\n  group_id   = 1\n  adlist_ids = [1]\n  domain_ids = [10]\n  client_ids = [20]\n}\n",
+		"import {\n  to = pihole_group_assignment.kids\n  id = \"1\"\n}\n",
+		"# skipped: the members of the Default group, which pihole_group_assignment does not manage\n",
+		"# skipped: 2 domain list entries, no resource type manages them\n",
+		"# skipped: 2 clients themselves, no resource type creates them\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected the configuration to contain:\n%s\ngot:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "allowlists") {
+		t.Errorf("expected no allowlists without allow adlists, got:\n%s", out.String())
+	}
+
+	// Pihole v5 only has records
+	out.Reset()
+	if err := newFakePihole(t).client().generateConfig(context.Background(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "# skipped: adlists, domains, groups and clients, managed with Pihole v6 only\n") {
+		t.Errorf("expected the v6 items to be reported skipped, got:\n%s", out.String())
+	}
+}
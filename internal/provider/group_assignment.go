@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// groupTables are the tables whose items are assigned to groups.
+var groupTables = []string{listsTable, domainsTable, clientsTable}
+
+// groupTableItems names the items of each table in errors.
+var groupTableItems = map[string]string{
+	listsTable:   "adlist",
+	domainsTable: "domain",
+	clientsTable: "client",
+}
+
+// groupMembers are the IDs of the items assigned to a group, by table.
+type groupMembers map[string][]int64
+
+// contains reports whether the item of the table is a member.
+func (m groupMembers) contains(table string, id int64) bool {
+	for _, member := range m[table] {
+		if member == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetGroupMembers returns the items assigned to a group, and whether the group
+// exists.
+func (c *piholeClient) GetGroupMembers(ctx context.Context, groupID int64) (groupMembers, bool, error) {
+	api, err := c.v6("pihole_group_assignment")
+	if err != nil {
+		return nil, false, err
+	}
+
+	if found, err := api.hasGroup(ctx, groupID); err != nil || !found {
+		return nil, found, err
+	}
+
+	members := groupMembers{}
+	for _, table := range groupTables {
+		items, err := api.getItems(ctx, table)
+		if err != nil {
+			return nil, false, err
+		}
+		members[table] = []int64{}
+		for _, item := range items {
+			if item.hasGroup(groupID) {
+				members[table] = append(members[table], item.ID)
+			}
+		}
+		sort.Slice(members[table], func(a, b int) bool { return members[table][a] < members[table][b] })
+	}
+
+	return members, true, nil
+}
+
+// SetGroupMembers assigns the group to the items of assign, and removes it
+// from the items of unassign not in assign. The other items are left alone.
+func (c *piholeClient) SetGroupMembers(ctx context.Context, groupID int64, assign groupMembers, unassign groupMembers) error {
+	api, err := c.v6("pihole_group_assignment")
+	if err != nil {
+		return err
+	}
+
+	// Retries read the items again and only write the ones still to change,
+	// so a write whose outcome is unknown is simply sent again
+	return c.write(ctx,
+		func() error { return api.setGroupMembers(ctx, groupID, assign, unassign) },
+		func() (bool, error) { return false, nil },
+	)
+}
+
+// hasGroup reports whether the group exists.
+func (a *v6API) hasGroup(ctx context.Context, groupID int64) (bool, error) {
+	groups, err := a.getGroups(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, group := range groups {
+		if group.ID == groupID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// setGroupMembers updates the groups of the items whose membership changes.
+func (a *v6API) setGroupMembers(ctx context.Context, groupID int64, assign groupMembers, unassign groupMembers) error {
+	if len(assign[listsTable])+len(assign[domainsTable])+len(assign[clientsTable]) > 0 {
+		found, err := a.hasGroup(ctx, groupID)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("group %d does not exist", groupID)
+		}
+	}
+
+	for _, table := range groupTables {
+		if len(assign[table])+len(unassign[table]) == 0 {
+			continue
+		}
+
+		items, err := a.getItems(ctx, table)
+		if err != nil {
+			return err
+		}
+
+		found := map[int64]bool{}
+		for _, item := range items {
+			found[item.ID] = true
+		}
+		for _, id := range assign[table] {
+			if !found[id] {
+				return fmt.Errorf("%s %d does not exist", groupTableItems[table], id)
+			}
+		}
+
+		for _, item := range items {
+			assigned := assign.contains(table, item.ID)
+			if assigned == item.hasGroup(groupID) || (!assigned && !unassign.contains(table, item.ID)) {
+				continue
+			}
+			if err := a.putItem(ctx, table, item, item.withGroup(groupID, assigned)); err != nil {
+				return fmt.Errorf("could not update %s %d: %w", groupTableItems[table], item.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,322 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &GroupAssignmentResource{}
+	_ resource.ResourceWithConfigure   = &GroupAssignmentResource{}
+	_ resource.ResourceWithImportState = &GroupAssignmentResource{}
+)
+
+// groupAssignmentAttributes are the attributes listing the members of each
+// table.
+var groupAssignmentAttributes = map[string]string{
+	listsTable:   "adlist_ids",
+	domainsTable: "domain_ids",
+	clientsTable: "client_ids",
+}
+
+// NewGroupAssignmentResource is a helper function to simplify the provider implementation.
+func NewGroupAssignmentResource() resource.Resource {
+	return &GroupAssignmentResource{}
+}
+
+// GroupAssignmentResource is the resource implementation.
+type GroupAssignmentResource struct {
+	client *piholeClient
+}
+
+// GroupAssignmentResourceModel maps the resource schema data.
+type GroupAssignmentResourceModel struct {
+	LastUpdated types.String `tfsdk:"last_updated"`
+	GroupID     types.Int64  `tfsdk:"group_id"`
+	AdlistIDs   types.Set    `tfsdk:"adlist_ids"`
+	DomainIDs   types.Set    `tfsdk:"domain_ids"`
+	ClientIDs   types.Set    `tfsdk:"client_ids"`
+}
+
+// Metadata returns the resource type name.
+func (r *GroupAssignmentResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_group_assignment"
+}
+
+// Schema defines the schema for the resource.
+func (r *GroupAssignmentResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	ids := func(items string) schema.SetAttribute {
+		return schema.SetAttribute{
+			Description: fmt.Sprintf("IDs of the %s assigned to the group. The group is removed from the other %s, "+
+				"so that %s added to the group outside of Terraform show in the plan. Defaults to none.", items, items, items),
+			ElementType: types.Int64Type,
+			Optional:    true,
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Authoritative members of a Pihole group: the adlists, domains and clients assigned to the " +
+			"group, and only them. Other groups of the members are left alone, so that several groups can be " +
+			"managed on the same items. The Default group, which Pihole assigns to every new item, cannot be managed. " +
+			"Requires Pihole v6.",
+		Attributes: map[string]schema.Attribute{
+			"last_updated": schema.StringAttribute{
+				Description: "Timestamp of the last Terraform update of the group members.",
+				Computed:    true,
+			},
+			"group_id": schema.Int64Attribute{
+				Description: "ID of the group. The Default group, 0, cannot be managed: Pihole assigns it to every new " +
+					"adlist, domain and client.",
+				Required: true,
+				Validators: []validator.Int64{
+					int64RangeValidator{min: defaultGroupID + 1, max: math.MaxInt64},
+				},
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"adlist_ids": ids("adlists"),
+			"domain_ids": ids("domains"),
+			"client_ids": ids("clients"),
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *GroupAssignmentResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*piholeClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *piholeClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// members returns the members of the model, null sets holding none.
+func (m *GroupAssignmentResourceModel) members(ctx context.Context) (groupMembers, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	members := groupMembers{}
+	for table, set := range map[string]types.Set{listsTable: m.AdlistIDs, domainsTable: m.DomainIDs, clientsTable: m.ClientIDs} {
+		ids := []int64{}
+		if !set.IsNull() {
+			diags.Append(set.ElementsAs(ctx, &ids, false)...)
+		}
+		members[table] = ids
+	}
+
+	return members, diags
+}
+
+// setMembers sets the members of the model, keeping null the sets that were
+// null while the group has no such members.
+func (m *GroupAssignmentResourceModel) setMembers(members groupMembers) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for table, set := range map[string]*types.Set{listsTable: &m.AdlistIDs, domainsTable: &m.DomainIDs, clientsTable: &m.ClientIDs} {
+		if set.IsNull() && len(members[table]) == 0 {
+			continue
+		}
+		elements := make([]attr.Value, 0, len(members[table]))
+		for _, id := range members[table] {
+			elements = append(elements, types.Int64Value(id))
+		}
+		value, d := types.SetValue(types.Int64Type, elements)
+		diags.Append(d...)
+		*set = value
+	}
+
+	return diags
+}
+
+// Create assigns the group to the members.
+func (r *GroupAssignmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan GroupAssignmentResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	members, diags := plan.members(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Adopting the group removes it from the items not listed
+	current, found, err := r.client.GetGroupMembers(ctx, plan.GroupID.ValueInt64())
+	if err == nil && !found {
+		err = fmt.Errorf("group %d does not exist", plan.GroupID.ValueInt64())
+	}
+	if err == nil {
+		err = r.client.SetGroupMembers(ctx, plan.GroupID.ValueInt64(), members, current)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error setting Pihole group members",
+			fmt.Sprintf("Could not assign group %d, unexpected error: %s", plan.GroupID.ValueInt64(), err),
+		)
+		return
+	}
+	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read refreshes the members, reporting the items assigned to the group
+// outside of Terraform.
+func (r *GroupAssignmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state GroupAssignmentResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	known, diags := state.members(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	members, found, err := r.client.GetGroupMembers(ctx, state.GroupID.ValueInt64())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Pihole group members",
+			fmt.Sprintf("Could not read the members of group %d: %s", state.GroupID.ValueInt64(), err),
+		)
+		return
+	}
+	if !found {
+		tflog.Warn(ctx, "Group no longer exists, removing it from the state", map[string]any{"group_id": state.GroupID.ValueInt64()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	for table, ids := range members {
+		for _, id := range ids {
+			if !known.contains(table, id) {
+				tflog.Warn(ctx, "Item assigned to the group outside of Terraform", map[string]any{
+					"group_id":  state.GroupID.ValueInt64(),
+					"attribute": groupAssignmentAttributes[table],
+					"id":        id,
+				})
+			}
+		}
+	}
+
+	resp.Diagnostics.Append(state.setMembers(members)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update assigns the group to the new members and removes it from the others.
+func (r *GroupAssignmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state GroupAssignmentResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	members, diags := plan.members(ctx)
+	resp.Diagnostics.Append(diags...)
+	previous, diags := state.members(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.SetGroupMembers(ctx, plan.GroupID.ValueInt64(), members, previous); err != nil {
+		resp.Diagnostics.AddError(
+			"Error updating Pihole group members",
+			fmt.Sprintf("Could not update the members of group %d, unexpected error: %s", plan.GroupID.ValueInt64(), err),
+		)
+		return
+	}
+	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes the group from the members, leaving the other items alone.
+func (r *GroupAssignmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state GroupAssignmentResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	members, diags := state.members(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.SetGroupMembers(ctx, state.GroupID.ValueInt64(), groupMembers{}, members); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting Pihole group members",
+			fmt.Sprintf("Could not remove group %d from its members, unexpected error: %s", state.GroupID.ValueInt64(), err),
+		)
+	}
+}
+
+// ImportState adopts the current members of the group whose ID is the import
+// ID.
+func (r *GroupAssignmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	groupID, err := strconv.ParseInt(req.ID, 10, 64)
+	if err != nil || groupID < 0 {
+		resp.Diagnostics.AddError(
+			"Error Importing Pihole group members",
+			fmt.Sprintf("%q is not a group ID, such as 1.", req.ID),
+		)
+		return
+	}
+	if groupID == defaultGroupID {
+		resp.Diagnostics.AddError(
+			"Error Importing Pihole group members",
+			"The members of the Default group cannot be managed, as Pihole assigns it to every new adlist, domain and client.",
+		)
+		return
+	}
+
+	diags := resp.State.SetAttribute(ctx, path.Root("group_id"), groupID)
+	resp.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// fakeGravity fills the gravity database of the fake Pihole with a group,
+// assigned to adlist 1, domain 10 and client 20 besides the Default group.
+func fakeGravity(fake *fakePihole) {
+	enabled := true
+	fake.groups = []group{{ID: 0, Name: "Default", Enabled: true}, {ID: 1, Name: "kids", Enabled: true}}
+	fake.items = map[string][]listItem{
+		listsTable: {
+			{ID: 1, Address: "https://example.com/hosts", Type: "block", Enabled: &enabled, Groups: []int64{0, 1}},
+			{ID: 2, Address: "https://example.org/hosts", Type: "block", Enabled: &enabled, Groups: []int64{0}},
+		},
+		domainsTable: {
+			{ID: 10, Domain: "games.example.com", Type: "deny", Kind: "exact", Enabled: &enabled, Groups: []int64{1}},
+			{ID: 11, Domain: `(^|\.)social\.example$`, Type: "deny", Kind: "regex", Enabled: &enabled, Groups: []int64{0}},
+		},
+		clientsTable: {
+			{ID: 20, Client: "192.168.1.20", Groups: []int64{0, 1}},
+			{ID: 21, Client: "192.168.1.21", Groups: []int64{0}},
+		},
+	}
+}
+
+func TestClientGroupMembers(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	fakeGravity(fake)
+	client := fake.client()
+
+	members, found, err := client.GetGroupMembers(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := groupMembers{listsTable: {1}, domainsTable: {10}, clientsTable: {20}}
+	if !found || !reflect.DeepEqual(members, expected) {
+		t.Fatalf("expected %v, got %v (found %v)", expected, members, found)
+	}
+
+	// Move the group from adlist 1 to adlist 2 and add domain 11, leaving
+	// the clients alone
+	assign := groupMembers{listsTable: {2}, domainsTable: {10, 11}, clientsTable: {20}}
+	if err := client.SetGroupMembers(ctx, 1, assign, members); err != nil {
+		t.Fatal(err)
+	}
+	if members, _, _ = client.GetGroupMembers(ctx, 1); !reflect.DeepEqual(members, assign) {
+		t.Fatalf("expected %v, got %v", assign, members)
+	}
+	if groups := fake.items[listsTable][0].Groups; !reflect.DeepEqual(groups, []int64{0}) {
+		t.Fatalf("expected adlist 1 to keep the Default group only, got %v", groups)
+	}
+	if groups := fake.items[domainsTable][1].Groups; !reflect.DeepEqual(groups, []int64{0, 1}) {
+		t.Fatalf("expected domain 11 to keep the Default group, got %v", groups)
+	}
+	if calls := fake.count("lists/set") + fake.count("domains/set") + fake.count("clients/set"); calls != 3 {
+		t.Fatalf("expected 3 items to be updated, got %d", calls)
+	}
+
+	// Items assigned outside of the managed ones are left alone
+	if err := client.SetGroupMembers(ctx, 1, groupMembers{}, groupMembers{listsTable: {2}}); err != nil {
+		t.Fatal(err)
+	}
+	expected = groupMembers{listsTable: {}, domainsTable: {10, 11}, clientsTable: {20}}
+	if members, _, _ = client.GetGroupMembers(ctx, 1); !reflect.DeepEqual(members, expected) {
+		t.Fatalf("expected %v, got %v", expected, members)
+	}
+
+	if err := client.SetGroupMembers(ctx, 1, groupMembers{clientsTable: {99}}, nil); err == nil {
+		t.Fatal("expected an error assigning an unknown client")
+	}
+	if err := client.SetGroupMembers(ctx, 7, groupMembers{clientsTable: {20}}, nil); err == nil {
+		t.Fatal("expected an error assigning an unknown group")
+	}
+	if _, found, err := client.GetGroupMembers(ctx, 7); err != nil || found {
+		t.Fatalf("expected group 7 not to be found, got %v", err)
+	}
+
+	if _, _, err := newFakePihole(t).client().GetGroupMembers(ctx, 1); err == nil {
+		t.Fatal("expected an error on Pihole v5")
+	}
+}
@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// Failures of the health check run while configuring the provider, each
+// calling for a different fix.
+var (
+	errUnreachable        = errors.New("Pihole is unreachable")
+	errWrongAPIPath       = errors.New("the URL does not serve the Pihole API")
+	errBadCredentials     = errors.New("Pihole rejected the credentials")
+	errUnsupportedVersion = errors.New("the Pihole version is not supported")
+)
+
+// majorVersionRegexp extracts the major version from Pihole version strings
+// such as "v5.18.2" or "development-v6.0".
+var majorVersionRegexp = regexp.MustCompile(`v(\d+)\.`)
+
+// majorVersion returns the major version of a Pihole version string, or 0
+// when it cannot be parsed, as with custom builds.
+func majorVersion(version string) int {
+	match := majorVersionRegexp.FindStringSubmatch(version)
+	if match == nil {
+		return 0
+	}
+
+	major, _ := strconv.Atoi(match[1])
+
+	return major
+}
+
+// Check verifies that api.php answers, that its version is supported and that
+// the token is accepted. The v5 API answers unauthenticated calls with an
+// empty array rather than an error, so the token is checked explicitly.
+func (a *v5API) Check(ctx context.Context) error {
+	var versions map[string]interface{}
+	if err := a.getJSON(ctx, url.Values{"versions": {""}}, &versions); err != nil {
+		return err
+	}
+
+	core, _ := versions["core_current"].(string)
+	if major := majorVersion(core); major != 0 && major != 5 {
+		return fmt.Errorf("%w: Pihole %s serves api.php, only Pihole v5 and v6 are supported", errUnsupportedVersion, core)
+	}
+
+	var status map[string]interface{}
+	if err := a.getJSON(ctx, url.Values{"status": {""}, "auth": {a.base.APIKey}}, &status); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return fmt.Errorf("%w: the API token is not valid", errBadCredentials)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// getJSON sends a raw request to api.php, for the calls the Pihole API client
+// does not cover, and decodes the answer into out.
+func (a *v5API) getJSON(ctx context.Context, params url.Values, out interface{}) error {
+	u, err := url.Parse(a.base.BaseURL)
+	if err != nil {
+		return err
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(maskSecrets(ctx, a.base.APIKey), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := a.base.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errUnreachable, redactError(err))
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s answered %s", errWrongAPIPath, a.base.BaseURL, res.Status)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&raw); err != nil {
+		return fmt.Errorf("%w: %s did not answer JSON", errWrongAPIPath, a.base.BaseURL)
+	}
+
+	return json.Unmarshal(raw, out)
+}
+
+// Check verifies that the v6 API accepts the password and that its version
+// is supported.
+func (a *v6API) Check(ctx context.Context) error {
+	if _, err := a.session(ctx); err != nil {
+		var apiErr *v6Error
+		if !errors.As(err, &apiErr) {
+			return fmt.Errorf("%w: %s", errUnreachable, err)
+		}
+		if apiErr.Status == http.StatusUnauthorized {
+			return fmt.Errorf("%w: %s", errBadCredentials, err)
+		}
+		return err
+	}
+
+	var version struct {
+		Version struct {
+			FTL struct {
+				Local struct {
+					Version string `json:"version"`
+				} `json:"local"`
+			} `json:"ftl"`
+		} `json:"version"`
+	}
+	if err := a.do(ctx, http.MethodGet, "/info/version", nil, &version); err != nil {
+		return err
+	}
+
+	ftl := version.Version.FTL.Local.Version
+	if major := majorVersion(ftl); major != 0 && major < 6 {
+		return fmt.Errorf("%w: FTL %s serves the v6 API, only Pihole v5 and v6 are supported", errUnsupportedVersion, ftl)
+	}
+
+	return nil
+}
+
+// addHealthCheckDiagnostic turns a failed health check into a single
+// diagnostic telling how to fix the provider configuration. credential is
+// the attribute holding the credential used.
+func addHealthCheckDiagnostic(diags *diag.Diagnostics, err error, credential path.Path) {
+	switch {
+	case errors.Is(err, errUnreachable):
+		diags.AddAttributeError(
+			path.Root("url"),
+			"Unable to Reach Pihole",
+			"The provider cannot connect to Pihole. Check that the url attribute or the PIHOLE_API_URL environment variable "+
+				"points to a running Pihole, and the network, proxy and TLS settings of the provider.\n\n"+err.Error(),
+		)
+	case errors.Is(err, errWrongAPIPath):
+		diags.AddAttributeError(
+			path.Root("url"),
+			"Invalid Pihole API URL",
+			"The provider reached a server that does not serve the Pihole API. Set the url attribute or the PIHOLE_API_URL "+
+				"environment variable to the address of Pihole, such as https://pi.hole, or to the full URL of its API, "+
+				"such as https://pi.hole/api for Pihole v6 or https://pi.hole/admin/api.php for Pihole v5.\n\n"+err.Error(),
+		)
+	case errors.Is(err, errBadCredentials):
+		diags.AddAttributeError(
+			credential,
+			"Invalid Pihole Credentials",
+			"Pihole rejected the credentials of the provider. Check the web password, or the API token of Pihole v5 "+
+				"found in Settings > API, or the application password of Pihole v6.\n\n"+err.Error(),
+		)
+	case errors.Is(err, errUnsupportedVersion):
+		diags.AddAttributeError(
+			path.Root("url"),
+			"Unsupported Pihole Version",
+			"The provider supports Pihole v5 and v6. Upgrade Pihole to use it with this provider.\n\n"+err.Error(),
+		)
+	default:
+		diags.AddAttributeError(
+			path.Root("url"),
+			"Unable to Check Pihole",
+			"The provider could not check the connection to Pihole: "+err.Error(),
+		)
+	}
+}
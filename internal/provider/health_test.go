@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+
+	fakeV5 := newFakePihole(t)
+	fakeV6 := newFakePiholeV6(t)
+	fakeV4 := newFakePihole(t)
+	fakeV4.version = "v4.4"
+	html := newFakePihole(t)
+	html.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		_, _ = w.Write([]byte("<html></html>"))
+		return true
+	}
+	down := newFakePihole(t)
+	down.Close()
+
+	tests := map[string]struct {
+		url         string
+		credentials piholeCredentials
+		expected    error
+	}{
+		"v5":                {url: fakeV5.APIURL(), credentials: piholeCredentials{Password: fakePiholePassword}},
+		"v6":                {url: fakeV6.URL, credentials: piholeCredentials{Password: fakePiholePassword}},
+		"v5 wrong token":    {url: fakeV5.APIURL(), credentials: piholeCredentials{Token: "wrong"}, expected: errBadCredentials},
+		"v6 wrong password": {url: fakeV6.URL, credentials: piholeCredentials{Password: "wrong"}, expected: errBadCredentials},
+		"v4":                {url: fakeV4.APIURL(), credentials: piholeCredentials{Password: fakePiholePassword}, expected: errUnsupportedVersion},
+		"v5 wrong path":     {url: fakeV5.URL + "/api.php", credentials: piholeCredentials{Password: fakePiholePassword}, expected: errWrongAPIPath},
+		"v5 not JSON":       {url: html.APIURL(), credentials: piholeCredentials{Password: fakePiholePassword}, expected: errWrongAPIPath},
+		"v6 wrong path":     {url: fakeV6.URL + "/other", credentials: piholeCredentials{Password: fakePiholePassword}, expected: errWrongAPIPath},
+		"unreachable":       {url: down.URL, credentials: piholeCredentials{Password: fakePiholePassword}, expected: errUnreachable},
+		"unreachable v5":    {url: down.APIURL(), credentials: piholeCredentials{Password: fakePiholePassword}, expected: errUnreachable},
+	}
+
+	for name, test := range tests {
+		api, err := newPiholeAPI(ctx, test.url, test.credentials, http.DefaultClient)
+		if err == nil {
+			err = api.Check(ctx)
+		}
+		if test.expected == nil && err != nil {
+			t.Errorf("%s: expected the check to pass, got: %s", name, err)
+		}
+		if test.expected != nil && !errors.Is(err, test.expected) {
+			t.Errorf("%s: expected %q, got: %v", name, test.expected, err)
+		}
+	}
+}
+
+func TestMajorVersion(t *testing.T) {
+	tests := map[string]int{
+		"v5.18.2":          5,
+		"v6.0":             6,
+		"development-v6.1": 6,
+		"vDev-abc":         0,
+		"":                 0,
+	}
+
+	for version, expected := range tests {
+		if got := majorVersion(version); got != expected {
+			t.Errorf("majorVersion(%q): expected %d, got %d", version, expected, got)
+		}
+	}
+}
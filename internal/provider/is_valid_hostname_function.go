@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &IsValidHostnameFunction{}
+
+// NewIsValidHostnameFunction is a helper function to simplify the provider implementation.
+func NewIsValidHostnameFunction() function.Function {
+	return &IsValidHostnameFunction{}
+}
+
+// IsValidHostnameFunction is the function implementation.
+type IsValidHostnameFunction struct{}
+
+// Metadata returns the function name.
+func (f *IsValidHostnameFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "is_valid_hostname"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *IsValidHostnameFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Checks that a string is a valid hostname",
+		Description: "Returns whether a string is a valid RFC 1123 hostname, as required by the domain of DNS and " +
+			"CNAME records: dot separated labels of 1 to 63 letters, digits or hyphens, not starting or ending " +
+			"with a hyphen, for a total of at most 253 characters.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "s",
+				Description: "String to check.",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+// Run checks the string.
+func (f *IsValidHostnameFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var s string
+	resp.Error = req.Arguments.Get(ctx, &s)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, isValidHostname(s))
+}
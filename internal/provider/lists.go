@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// Tables of the gravity database holding items assigned to groups, named
+// after their API endpoint.
+const (
+	listsTable   = "lists"
+	domainsTable = "domains"
+	clientsTable = "clients"
+)
+
+// listItem is an adlist, a domain or a client of the gravity database. Each
+// table only fills the fields it knows.
+type listItem struct {
+	ID     int64   `json:"id"`
+	Groups []int64 `json:"groups"`
+
+	// Address is the URL of an adlist.
+	Address string `json:"address"`
+	// Domain is the domain or regular expression of a domain.
+	Domain string `json:"domain"`
+	// Client is the IP, subnet, MAC address, host name or interface of a
+	// client.
+	Client string `json:"client"`
+
+	// Type is block or allow for adlists, and allow or deny for domains.
+	Type string `json:"type"`
+	// Kind is exact or regex for domains.
+	Kind    string  `json:"kind"`
+	Comment *string `json:"comment"`
+	// Enabled is not reported for clients.
+	Enabled *bool `json:"enabled"`
+}
+
+// defaultGroupID is the ID of the Default group, which Pihole assigns to
+// every new adlist, domain and client.
+const defaultGroupID = 0
+
+// group is a group of the gravity database.
+type group struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// hasGroup reports whether the item is assigned to the group.
+func (i listItem) hasGroup(id int64) bool {
+	for _, group := range i.Groups {
+		if group == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withGroup returns the groups of the item with the group added or removed.
+func (i listItem) withGroup(id int64, assigned bool) []int64 {
+	groups := make([]int64, 0, len(i.Groups)+1)
+	for _, group := range i.Groups {
+		if group != id {
+			groups = append(groups, group)
+		}
+	}
+	if assigned {
+		groups = append(groups, id)
+	}
+	sort.Slice(groups, func(a, b int) bool { return groups[a] < groups[b] })
+
+	return groups
+}
+
+// getItems returns the items of a table.
+func (a *v6API) getItems(ctx context.Context, table string) ([]listItem, error) {
+	var answer map[string][]listItem
+	if err := a.do(ctx, http.MethodGet, "/"+table, nil, &answer); err != nil {
+		return nil, err
+	}
+
+	return answer[table], nil
+}
+
+// putItem replaces the groups of an item, sending its other fields back
+// unchanged.
+func (a *v6API) putItem(ctx context.Context, table string, item listItem, groups []int64) error {
+	body := map[string]interface{}{
+		"comment": item.Comment,
+		"groups":  groups,
+	}
+	if item.Enabled != nil {
+		body["enabled"] = *item.Enabled
+	}
+
+	var itemPath string
+	switch table {
+	case listsTable:
+		body["type"] = item.Type
+		itemPath = "/lists/" + url.PathEscape(item.Address) + "?" + url.Values{"type": {item.Type}}.Encode()
+	case domainsTable:
+		body["type"], body["kind"] = item.Type, item.Kind
+		itemPath = "/domains/" + item.Type + "/" + item.Kind + "/" + url.PathEscape(item.Domain)
+	case clientsTable:
+		itemPath = "/clients/" + url.PathEscape(item.Client)
+	default:
+		return fmt.Errorf("unknown table %s", table)
+	}
+
+	return a.do(ctx, http.MethodPut, itemPath, body, nil)
+}
+
+// getGroups returns the groups of the gravity database.
+func (a *v6API) getGroups(ctx context.Context) ([]group, error) {
+	var answer struct {
+		Groups []group `json:"groups"`
+	}
+	if err := a.do(ctx, http.MethodGet, "/groups", nil, &answer); err != nil {
+		return nil, err
+	}
+
+	return answer.Groups, nil
+}
@@ -0,0 +1,274 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &LocalDnsDomainResource{}
+	_ resource.ResourceWithConfigure   = &LocalDnsDomainResource{}
+	_ resource.ResourceWithImportState = &LocalDnsDomainResource{}
+)
+
+// localDNSDomainDefaults are the settings of a new Pihole, restored for the
+// domain and the managed settings when the resource is destroyed.
+var localDNSDomainDefaults = localDNSDomainSettings{
+	Domain:       localDNSDomain{Name: "lan"},
+	ExpandHosts:  false,
+	DomainNeeded: false,
+	BogusPriv:    true,
+}
+
+// localDNSDomainSettings are the local domain settings of the dns
+// configuration section.
+type localDNSDomainSettings struct {
+	Domain       localDNSDomain `json:"domain"`
+	ExpandHosts  bool           `json:"expandHosts"`
+	DomainNeeded bool           `json:"domainNeeded"`
+	BogusPriv    bool           `json:"bogusPriv"`
+}
+
+// NewLocalDnsDomainResource is a helper function to simplify the provider implementation.
+func NewLocalDnsDomainResource() resource.Resource {
+	return &LocalDnsDomainResource{}
+}
+
+// LocalDnsDomainResource is the resource implementation.
+type LocalDnsDomainResource struct {
+	client *piholeClient
+}
+
+// LocalDnsDomainResourceModel maps the resource schema data.
+type LocalDnsDomainResourceModel struct {
+	LastUpdated  types.String `tfsdk:"last_updated"`
+	Domain       types.String `tfsdk:"domain"`
+	ExpandHosts  types.Bool   `tfsdk:"expand_hosts"`
+	DomainNeeded types.Bool   `tfsdk:"domain_needed"`
+	BogusPriv    types.Bool   `tfsdk:"bogus_priv"`
+}
+
+// Metadata returns the resource type name.
+func (r *LocalDnsDomainResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_local_dns_domain"
+}
+
+// Schema defines the schema for the resource.
+func (r *LocalDnsDomainResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Local domain of Pihole and the related DNS settings. Pihole has a single local domain, so declare " +
+			"this resource once per Pihole. Optional settings left unset are not managed and keep their current value, " +
+			"also when the resource is destroyed, which restores the lan domain and the Pihole defaults of the managed " +
+			"settings. Requires Pihole v6.",
+		Attributes: map[string]schema.Attribute{
+			"last_updated": schema.StringAttribute{
+				Description: "Timestamp of the last Terraform update of the settings.",
+				Computed:    true,
+			},
+			"domain": schema.StringAttribute{
+				Description: "Local domain, such as lan, qualifying the short host names of DHCP clients and, with expand_hosts, of custom DNS records.",
+				Required:    true,
+				Validators: []validator.String{
+					hostnameValidator{},
+				},
+			},
+			"expand_hosts": schema.BoolAttribute{
+				Description: "Whether to append the local domain to the short host names of custom DNS records.",
+				Optional:    true,
+			},
+			"domain_needed": schema.BoolAttribute{
+				Description: "Whether to never forward queries for short host names to the upstream servers.",
+				Optional:    true,
+			},
+			"bogus_priv": schema.BoolAttribute{
+				Description: "Whether to never forward reverse lookups of private IP ranges to the upstream servers.",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *LocalDnsDomainResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*piholeClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *piholeClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// getSettings reads the local domain settings.
+func (r *LocalDnsDomainResource) getSettings(ctx context.Context) (localDNSDomainSettings, error) {
+	var settings localDNSDomainSettings
+	err := r.client.GetConfig(ctx, "pihole_local_dns_domain", "dns", &settings)
+
+	return settings, err
+}
+
+// apply writes the configured settings, then reads them back into model.
+func (r *LocalDnsDomainResource) apply(ctx context.Context, model *LocalDnsDomainResourceModel) error {
+	current, err := r.getSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{
+		current.Domain.Key(): model.Domain.ValueString(),
+	}
+	for key, value := range map[string]types.Bool{
+		"dns.expandHosts":  model.ExpandHosts,
+		"dns.domainNeeded": model.DomainNeeded,
+		"dns.bogusPriv":    model.BogusPriv,
+	} {
+		if !value.IsNull() && !value.IsUnknown() {
+			values[key] = value.ValueBool()
+		}
+	}
+
+	ctx = tflog.SetField(ctx, "settings", values)
+	if err := r.client.SetConfig(ctx, "pihole_local_dns_domain", values); err != nil {
+		return err
+	}
+
+	settings, err := r.getSettings(ctx)
+	if err != nil {
+		return err
+	}
+	model.setSettings(settings)
+	model.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+
+	return nil
+}
+
+// setSettings sets the domain and the managed settings of the model, those not
+// null, to the given settings.
+func (m *LocalDnsDomainResourceModel) setSettings(settings localDNSDomainSettings) {
+	m.Domain = types.StringValue(settings.Domain.Name)
+	for _, attribute := range []struct {
+		model *types.Bool
+		value bool
+	}{
+		{&m.ExpandHosts, settings.ExpandHosts},
+		{&m.DomainNeeded, settings.DomainNeeded},
+		{&m.BogusPriv, settings.BogusPriv},
+	} {
+		if !attribute.model.IsNull() {
+			*attribute.model = types.BoolValue(attribute.value)
+		}
+	}
+}
+
+// Create sets the local domain settings.
+func (r *LocalDnsDomainResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan LocalDnsDomainResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.apply(ctx, &plan); err != nil {
+		resp.Diagnostics.AddError(
+			"Error setting local DNS domain",
+			"Could not set the local DNS domain settings, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read resource information.
+func (r *LocalDnsDomainResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state LocalDnsDomainResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	settings, err := r.getSettings(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Pihole local DNS domain",
+			"Could not read the local DNS domain settings: "+err.Error(),
+		)
+		return
+	}
+	state.setSettings(settings)
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update sets the changed local domain settings.
+func (r *LocalDnsDomainResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan LocalDnsDomainResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.apply(ctx, &plan); err != nil {
+		resp.Diagnostics.AddError(
+			"Error updating local DNS domain",
+			"Could not update the local DNS domain settings, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete restores the Pihole defaults of the domain and the managed settings.
+func (r *LocalDnsDomainResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var defaults LocalDnsDomainResourceModel
+	diags := req.State.Get(ctx, &defaults)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	defaults.setSettings(localDNSDomainDefaults)
+
+	if err := r.apply(ctx, &defaults); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Deleting local DNS domain",
+			"Could not restore the default local DNS domain settings, unexpected error: "+err.Error(),
+		)
+	}
+}
+
+// ImportState adopts the local domain settings. The import ID only fills domain
+// until the read following the import replaces it with the current local
+// domain, and no optional setting is managed until the next apply writes the
+// configured ones.
+func (r *LocalDnsDomainResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("domain"), req, resp)
+}
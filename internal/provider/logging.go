@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// secretQueryParams are the query parameters carrying Pihole credentials.
+var secretQueryParams = []string{"auth", "token", "password", "sid"}
+
+// secretFieldKeys are the log field keys whose values are always masked.
+var secretFieldKeys = []string{"auth", "token", "password", "sid", "api_key", "pihole_token", "pihole_password"}
+
+// maskSecrets returns a context whose logs mask the credential fields and any
+// occurrence of the given secrets.
+func maskSecrets(ctx context.Context, secrets ...string) context.Context {
+	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, secretFieldKeys...)
+
+	for _, secret := range secrets {
+		// Masking an empty string would mask every log
+		if secret == "" {
+			continue
+		}
+		ctx = tflog.MaskAllFieldValuesStrings(ctx, secret)
+		ctx = tflog.MaskMessageStrings(ctx, secret)
+	}
+
+	return ctx
+}
+
+// redactURL returns the URL with the values of its credential query
+// parameters replaced, safe to log or show in a diagnostic.
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	redacted := false
+
+	for _, key := range secretQueryParams {
+		if query.Has(key) {
+			query.Set(key, "REDACTED")
+			redacted = true
+		}
+	}
+
+	if !redacted {
+		return u.String()
+	}
+
+	safe := *u
+	safe.RawQuery = query.Encode()
+
+	return safe.String()
+}
+
+// redactError removes the credentials from the URL reported by an HTTP error.
+func redactError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+			urlErr.URL = redactURL(u)
+		}
+	}
+
+	return err
+}
+
+// loggingTransport logs every request sent to the Pihole API at debug level,
+// credentials redacted.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	start := time.Now()
+
+	res, err := t.next.RoundTrip(req)
+
+	fields := map[string]interface{}{
+		"method":     req.Method,
+		"endpoint":   redactURL(req.URL),
+		"latency_ms": time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = redactError(err).Error()
+		tflog.Debug(ctx, "Pihole API request failed", fields)
+		return res, err
+	}
+
+	fields["status"] = res.StatusCode
+	tflog.Debug(ctx, "Pihole API request", fields)
+
+	return res, nil
+}
+
+// contextTransport sends the requests of the Pihole API client, which does
+// not take a context, with the context of the Terraform operation so they are
+// logged and cancelled along with it.
+type contextTransport struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.next.RoundTrip(req.WithContext(t.ctx))
+}
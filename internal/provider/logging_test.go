@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestRedactURL(t *testing.T) {
+	u, err := url.Parse("http://pi.hole/admin/api.php?customdns&action=get&auth=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redacted := redactURL(u)
+	if strings.Contains(redacted, "secret") {
+		t.Fatalf("expected the token to be redacted, got %s", redacted)
+	}
+	if !strings.Contains(redacted, "auth=REDACTED") || !strings.Contains(redacted, "action=get") {
+		t.Fatalf("expected the other parameters to be kept, got %s", redacted)
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	err := redactError(&url.Error{
+		Op:  "Get",
+		URL: "http://pi.hole/admin/api.php?auth=secret",
+		Err: errors.New("connection refused"),
+	})
+
+	if strings.Contains(err.Error(), "secret") {
+		t.Fatalf("expected the token to be redacted, got %s", err)
+	}
+}
+
+func TestClientLogsMaskToken(t *testing.T) {
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	fake := newFakePihole(t)
+	client := fake.client()
+
+	// A careless field must not leak the token either
+	ctx = tflog.SetField(ctx, "careless", fakePiholeToken)
+	if _, err := client.GetAllCustomDNS(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	logs := output.String()
+	if strings.Contains(logs, fakePiholeToken) {
+		t.Fatalf("expected the token to be masked, got logs: %s", logs)
+	}
+	for _, want := range []string{`"@message":"Pihole API request"`, `"method":"GET"`, `"status":200`, `"latency_ms"`, "/admin/api.php"} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("expected logs to contain %s, got: %s", want, logs)
+		}
+	}
+}
@@ -3,10 +3,11 @@ package provider
 import (
 	"context"
 	"os"
-
-	pihole "github.com/NicoFgrx/pihole-api-go/api"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
@@ -17,13 +18,30 @@ import (
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ provider.Provider = &piholeProvider{}
+	_ provider.Provider                       = &piholeProvider{}
+	_ provider.ProviderWithFunctions          = &piholeProvider{}
+	_ provider.ProviderWithEphemeralResources = &piholeProvider{}
 )
 
 // piholeProviderModel maps provider schema data to a Go type.
 type piholeProviderModel struct {
-	Url   types.String `tfsdk:"url"`
-	Token types.String `tfsdk:"token"`
+	Url                types.String `tfsdk:"url"`
+	Token              types.String `tfsdk:"token"`
+	Password           types.String `tfsdk:"password"`
+	TokenFile          types.String `tfsdk:"token_file"`
+	PasswordFile       types.String `tfsdk:"password_file"`
+	CredentialCommand  types.List   `tfsdk:"credential_command"`
+	StrictCnameTargets types.Bool   `tfsdk:"strict_cname_targets"`
+	RequestTimeout     types.String `tfsdk:"request_timeout"`
+	CACertFile         types.String `tfsdk:"ca_cert_file"`
+	CACertPEM          types.String `tfsdk:"ca_cert_pem"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
+	ClientCertFile     types.String `tfsdk:"client_cert_file"`
+	ClientKeyFile      types.String `tfsdk:"client_key_file"`
+	ClientCertPEM      types.String `tfsdk:"client_cert_pem"`
+	ClientKeyPEM       types.String `tfsdk:"client_key_pem"`
+	ProxyURL           types.String `tfsdk:"proxy_url"`
+	MaxRetries         types.Int64  `tfsdk:"max_retries"`
 }
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -55,14 +73,86 @@ func (p *piholeProvider) Schema(_ context.Context, _ provider.SchemaRequest, res
 		Description: "Interact with Pihole.",
 		Attributes: map[string]schema.Attribute{
 			"url": schema.StringAttribute{
-				Description: "URI for Pihole API. May also be provided via PIHOLE_API_URL environment variable.",
-				Optional:    true,
+				Description: "Address of Pihole, such as `pi.hole`, `https://pi.hole:8443` or `https://pi.hole/admin`, or the full URL of its API. " +
+					"The API endpoint is derived from the detected Pihole version. Defaults to `http://` without scheme. " +
+					"May also be provided via PIHOLE_API_URL environment variable.",
+				Optional: true,
 			},
 			"token": schema.StringAttribute{
-				Description: "Token for Pihole API. May also be provided via PIHOLE_TOKEN environment variable.",
+				Description: "Token for Pihole API: the v5 API token, or a v6 application password. " +
+					"The token is read from, in order: token, token_file, then the PIHOLE_TOKEN environment variable. " +
+					"When a token is found, it takes precedence over the password.",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"token_file": schema.StringAttribute{
+				Description: "Path to a file holding the token, such as a Docker or Kubernetes secret. Ignored when token is set.",
+				Optional:    true,
+			},
+			"password": schema.StringAttribute{
+				Description: "Web interface password of Pihole, from which the provider derives the v5 API token or opens a v6 API session. " +
+					"The password is read from, in order: password, password_file, credential_command, then the PIHOLE_PASSWORD environment variable.",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"password_file": schema.StringAttribute{
+				Description: "Path to a file holding the password, such as a Docker or Kubernetes secret. Ignored when password is set.",
+				Optional:    true,
+			},
+			"credential_command": schema.ListAttribute{
+				Description: "Command and arguments of a helper printing the password on its standard output, " +
+					"such as [\"pass\", \"show\", \"pihole\"] or [\"op\", \"read\", \"op://vault/pihole/password\"]. " +
+					"Ignored when password or password_file is set.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"strict_cname_targets": schema.BoolAttribute{
+				Description: "Fail creating a CNAME whose target does not resolve to a custom DNS record or CNAME managed by Pihole. " +
+					"Defaults to false, which only emits a warning. CNAME loops are always an error.",
+				Optional: true,
+			},
+			"request_timeout": schema.StringAttribute{
+				Description: "Timeout of each request to the Pihole API, as a duration such as \"30s\" or \"1m\". Defaults to 10s.",
+				Optional:    true,
+			},
+			"ca_cert_file": schema.StringAttribute{
+				Description: "Path to a PEM encoded CA certificate trusted when connecting to Pihole over HTTPS, in addition to the system pool.",
+				Optional:    true,
+			},
+			"ca_cert_pem": schema.StringAttribute{
+				Description: "PEM encoded CA certificate trusted when connecting to Pihole over HTTPS, in addition to the system pool.",
+				Optional:    true,
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Description: "Skip the verification of the Pihole server certificate. Only use for testing.",
+				Optional:    true,
+			},
+			"client_cert_file": schema.StringAttribute{
+				Description: "Path to a PEM encoded client certificate presented for mTLS. Requires client_key_file or client_key_pem.",
+				Optional:    true,
+			},
+			"client_key_file": schema.StringAttribute{
+				Description: "Path to the PEM encoded private key of the client certificate.",
+				Optional:    true,
+			},
+			"client_cert_pem": schema.StringAttribute{
+				Description: "PEM encoded client certificate presented for mTLS. Requires client_key_file or client_key_pem.",
+				Optional:    true,
+			},
+			"client_key_pem": schema.StringAttribute{
+				Description: "PEM encoded private key of the client certificate.",
 				Optional:    true,
 				Sensitive:   true,
 			},
+			"proxy_url": schema.StringAttribute{
+				Description: "URL of the HTTP proxy used to reach the Pihole API. Defaults to the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.",
+				Optional:    true,
+			},
+			"max_retries": schema.Int64Attribute{
+				Description: "Number of retries, with exponential backoff, of an API call failing because Pihole is unreachable or answers with a server error, " +
+					"as happens while FTL restarts. Writes are only retried after checking they were not applied. Defaults to 3, set to 0 to disable retries.",
+				Optional: true,
+			},
 		},
 	}
 }
@@ -80,21 +170,38 @@ func (p *piholeProvider) Configure(ctx context.Context, req provider.ConfigureRe
 	if config.Url.IsUnknown() {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("url"),
-			"Unknown PiHole API Host",
-			"The provider cannot create the PiHole API client as there is an unknown configuration value for the PiHole API host. "+
-				"Either target apply the source of the value first, set the value statically in the configuration, or use the PIHOLE_HOST environment variable.",
+			"Unknown PiHole API URL",
+			"The provider cannot create the PiHole API client as there is an unknown configuration value for the PiHole API URL. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the PIHOLE_API_URL environment variable.",
 		)
 	}
 
 	if config.Token.IsUnknown() {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("token"),
-			"Unknown PiHole API Password",
-			"The provider cannot create the PiHole API client as there is an unknown configuration value for the PiHole API password. "+
+			"Unknown PiHole API Token",
+			"The provider cannot create the PiHole API client as there is an unknown configuration value for the PiHole API token. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the PIHOLE_TOKEN environment variable.",
+		)
+	}
+
+	if config.Password.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("password"),
+			"Unknown PiHole Password",
+			"The provider cannot create the PiHole API client as there is an unknown configuration value for the PiHole password. "+
 				"Either target apply the source of the value first, set the value statically in the configuration, or use the PIHOLE_PASSWORD environment variable.",
 		)
 	}
 
+	if config.TokenFile.IsUnknown() || config.PasswordFile.IsUnknown() || config.CredentialCommand.IsUnknown() {
+		resp.Diagnostics.AddError(
+			"Unknown PiHole Credential Source",
+			"The provider cannot create the PiHole API client as there is an unknown configuration value for token_file, password_file or credential_command. "+
+				"Either target apply the source of the value first, or set the value statically in the configuration.",
+		)
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -104,13 +211,53 @@ func (p *piholeProvider) Configure(ctx context.Context, req provider.ConfigureRe
 
 	url := os.Getenv("PIHOLE_API_URL")
 	token := os.Getenv("PIHOLE_TOKEN")
+	password := os.Getenv("PIHOLE_PASSWORD")
 
 	if !config.Url.IsNull() {
 		url = config.Url.ValueString()
 	}
 
-	if !config.Token.IsNull() {
+	// Secrets are read from the first source set, see the attribute
+	// descriptions for the order.
+
+	var err error
+	switch {
+	case !config.Token.IsNull():
 		token = config.Token.ValueString()
+	case !config.TokenFile.IsNull():
+		if token, err = readSecretFile(config.TokenFile.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("token_file"),
+				"Unable to Read Pihole API Token",
+				"The provider cannot create the Pihole API client as the token file could not be read: "+err.Error(),
+			)
+		}
+	}
+
+	switch {
+	case !config.Password.IsNull():
+		password = config.Password.ValueString()
+	case !config.PasswordFile.IsNull():
+		if password, err = readSecretFile(config.PasswordFile.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("password_file"),
+				"Unable to Read Pihole Password",
+				"The provider cannot create the Pihole API client as the password file could not be read: "+err.Error(),
+			)
+		}
+	case !config.CredentialCommand.IsNull():
+		var command []string
+		resp.Diagnostics.Append(config.CredentialCommand.ElementsAs(ctx, &command, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if password, err = runCredentialCommand(ctx, command); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("credential_command"),
+				"Unable to Run Pihole Credential Command",
+				"The provider cannot create the Pihole API client as the credential command failed: "+err.Error(),
+			)
+		}
 	}
 
 	// If any of the expected configurations are missing, return
@@ -118,40 +265,129 @@ func (p *piholeProvider) Configure(ctx context.Context, req provider.ConfigureRe
 
 	if url == "" {
 		resp.Diagnostics.AddAttributeError(
-			path.Root("host"),
-			"Missing HashiCups API Host",
-			"The provider cannot create the Pihole API client as there is a missing or empty value for the HashiCups API host. "+
-				"Set the host value in the configuration or use the PIHOLE_API_URL environment variable. "+
+			path.Root("url"),
+			"Missing Pihole API URL",
+			"The provider cannot create the Pihole API client as there is a missing or empty value for the Pihole API URL. "+
+				"Set the url value in the configuration or use the PIHOLE_API_URL environment variable. "+
 				"If either is already set, ensure the value is not empty.",
 		)
 	}
 
-	if token == "" {
+	if token == "" && password == "" && !resp.Diagnostics.HasError() {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("token"),
-			"Missing HashiCups API Password",
-			"The provider cannot create the HashiCups API client as there is a missing or empty value for the HashiCups API password. "+
-				"Set the password value in the configuration or use the PIHOLE_TOKEN environment variable. "+
+			"Missing Pihole API Credentials",
+			"The provider cannot create the Pihole API client as there is a missing or empty value for both the Pihole API token and password. "+
+				"Set the token or password value in the configuration or use the PIHOLE_TOKEN or PIHOLE_PASSWORD environment variables. "+
 				"If either is already set, ensure the value is not empty.",
 		)
 	}
 
+	var timeout time.Duration
+	if !config.RequestTimeout.IsNull() {
+		var err error
+		timeout, err = time.ParseDuration(config.RequestTimeout.ValueString())
+		if err != nil || timeout <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("request_timeout"),
+				"Invalid Pihole API Request Timeout",
+				"The provider cannot create the Pihole API client as the request timeout "+config.RequestTimeout.String()+
+					" is not a positive duration such as \"30s\" or \"1m\".",
+			)
+		}
+	}
+
+	maxRetries := int64(defaultMaxRetries)
+	if !config.MaxRetries.IsNull() {
+		maxRetries = config.MaxRetries.ValueInt64()
+		if maxRetries < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_retries"),
+				"Invalid Pihole API Max Retries",
+				"The provider cannot create the Pihole API client as max_retries must not be negative.",
+			)
+		}
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	httpClient, err := newHTTPClient(transportConfig{
+		Timeout:            timeout,
+		CACertFile:         config.CACertFile.ValueString(),
+		CACertPEM:          config.CACertPEM.ValueString(),
+		InsecureSkipVerify: config.InsecureSkipVerify.ValueBool(),
+		ClientCertFile:     config.ClientCertFile.ValueString(),
+		ClientKeyFile:      config.ClientKeyFile.ValueString(),
+		ClientCertPEM:      config.ClientCertPEM.ValueString(),
+		ClientKeyPEM:       config.ClientKeyPEM.ValueString(),
+		ProxyURL:           config.ProxyURL.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Configure Pihole API Transport",
+			"The provider cannot create the Pihole API client as the HTTP transport settings are invalid: "+err.Error(),
+		)
+		return
+	}
+
+	ctx = maskSecrets(ctx, token, password)
+	ctx = tflog.SetField(ctx, "pihole_url", url)
+	// The credentials themselves are never attached to the logs
+	authMethod := "none"
+	switch {
+	case token != "":
+		authMethod = "token"
+	case password != "":
+		authMethod = "password"
+	}
+	ctx = tflog.SetField(ctx, "auth_method", authMethod)
+	tflog.Debug(ctx, "Creating Pihole client")
+
+	// Detect the API version to derive the matching credential
+	credential := path.Root("password")
+	if token != "" {
+		credential = path.Root("token")
+	}
+	api, err := newPiholeAPI(ctx, url, piholeCredentials{Token: token, Password: password}, newRetryClient(httpClient, int(maxRetries)))
+	if err != nil {
+		addHealthCheckDiagnostic(&resp.Diagnostics, err, credential)
+		return
+	}
+	trackSession(api)
+
+	// Fail early with an actionable error rather than on the first resource
+	if err := api.Check(ctx); err != nil {
+		addHealthCheckDiagnostic(&resp.Diagnostics, err, credential)
+		return
+	}
+
 	// Create a new pihole client using the configuration values
-	client := pihole.NewClient(url, token)
+	client := newPiholeClient(api)
+	client.strictCnameTargets = config.StrictCnameTargets.ValueBool()
+	client.maxRetries = int(maxRetries)
 
-	// Make the HashiCups client available during DataSource and Resource
-	// type Configure methods.
+	// Make the Pihole client available during DataSource, Resource and
+	// EphemeralResource type Configure methods.
 	resp.DataSourceData = client
 	resp.ResourceData = client
+	resp.EphemeralResourceData = client
+
+	tflog.Info(ctx, "Configured Pihole client", map[string]any{"success": true})
 }
 
 // DataSources defines the data sources implemented in the provider.
 func (p *piholeProvider) DataSources(_ context.Context) []func() datasource.DataSource {
-	return nil
+	return []func() datasource.DataSource{
+		NewTeleporterBackupDataSource,
+		NewQueriesDataSource,
+		NewTopDomainsDataSource,
+		NewTopBlockedDomainsDataSource,
+		NewTopClientsDataSource,
+		NewUpstreamsDataSource,
+		NewDomainSearchDataSource,
+	}
 }
 
 // Resources defines the resources implemented in the provider.
@@ -159,5 +395,29 @@ func (p *piholeProvider) Resources(_ context.Context) []func() resource.Resource
 	return []func() resource.Resource{
 		NewDnsRecordResource,
 		NewCnameResource,
+		NewTeleporterRestoreResource,
+		NewDnsExtraRecordResource,
+		NewLocalDnsDomainResource,
+		NewFtlSettingsResource,
+		NewConfigResource,
+		NewGroupAssignmentResource,
+		NewAdlistsResource,
+	}
+}
+
+// EphemeralResources defines the ephemeral resources implemented in the provider.
+func (p *piholeProvider) EphemeralResources(_ context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewSessionEphemeralResource,
+	}
+}
+
+// Functions defines the functions implemented in the provider.
+func (p *piholeProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewFqdnFunction,
+		NewReverseNameFunction,
+		NewIsValidHostnameFunction,
+		NewRegexForDomainFunction,
 	}
 }
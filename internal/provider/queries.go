@@ -0,0 +1,313 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queryStatusNames are the status of queries in Pihole v6, indexed by their
+// number in the Pihole v5 API.
+var queryStatusNames = []string{
+	"UNKNOWN", "GRAVITY", "FORWARDED", "CACHE", "REGEX", "DENYLIST", "EXTERNAL_BLOCKED_IP",
+	"EXTERNAL_BLOCKED_NULL", "EXTERNAL_BLOCKED_NXRA", "GRAVITY_CNAME", "REGEX_CNAME", "DENYLIST_CNAME",
+	"RETRIED", "RETRIED_DNSSEC", "IN_PROGRESS", "DBBUSY", "SPECIAL_DOMAIN", "CACHE_STALE",
+	"EXTERNAL_BLOCKED_EDE15",
+}
+
+// queryStatuses are the status filtering queries, in documentation order.
+var queryStatuses = []string{"blocked", "forwarded", "cached"}
+
+// queryStatus returns the status of a query, blocked, forwarded, cached or
+// unknown, from its detailed status. Statuses added by later Pihole versions
+// are unknown until listed here.
+func queryStatus(detail string) string {
+	switch {
+	case detail == "CACHE" || detail == "CACHE_STALE":
+		return "cached"
+	case detail == "FORWARDED" || detail == "IN_PROGRESS" || strings.HasPrefix(detail, "RETRIED"):
+		return "forwarded"
+	case strings.HasPrefix(detail, "EXTERNAL_BLOCKED"):
+		return "blocked"
+	}
+
+	switch strings.TrimSuffix(detail, "_CNAME") {
+	case "GRAVITY", "REGEX", "DENYLIST", "DBBUSY", "SPECIAL_DOMAIN":
+		return "blocked"
+	}
+
+	return "unknown"
+}
+
+// queryStatusDetails returns the detailed statuses of a status, blocked,
+// forwarded or cached.
+func queryStatusDetails(status string) []string {
+	var details []string
+	for _, detail := range queryStatusNames {
+		if queryStatus(detail) == status {
+			details = append(details, detail)
+		}
+	}
+
+	return details
+}
+
+// piholeQuery is a query of the Pihole query log.
+type piholeQuery struct {
+	Time   time.Time
+	Type   string
+	Domain string
+	Client string
+	// ClientName is the host name of the client, when known.
+	ClientName string
+	Detail     string
+	Upstream   string
+}
+
+// queryFilter selects queries of the query log. Zero fields select all the
+// queries.
+type queryFilter struct {
+	From  time.Time
+	Until time.Time
+	// Client is the IP address or the name of the client.
+	Client string
+	// Domain is a domain, or a pattern such as *.example.com.
+	Domain string
+	// Status is blocked, forwarded or cached.
+	Status string
+	// Detail is a detailed status of Status, such as GRAVITY, the v6 API
+	// filtering on a single one.
+	Detail string
+	Type   string
+	// Limit is the number of most recent queries returned.
+	Limit int
+}
+
+// match reports whether the filter selects the query.
+func (f queryFilter) match(q piholeQuery) bool {
+	if (!f.From.IsZero() && q.Time.Before(f.From)) || (!f.Until.IsZero() && q.Time.After(f.Until)) {
+		return false
+	}
+	if f.Client != "" && f.Client != q.Client && f.Client != q.ClientName {
+		return false
+	}
+	if f.Domain != "" {
+		if matched, _ := path.Match(strings.ToLower(f.Domain), strings.ToLower(q.Domain)); !matched {
+			return false
+		}
+	}
+	if f.Status != "" && f.Status != queryStatus(q.Detail) {
+		return false
+	}
+
+	return f.Type == "" || strings.EqualFold(f.Type, q.Type)
+}
+
+// GetQueries returns the most recent queries of the query log selected by the
+// filter, newest first. The filter is also matched against the queries
+// returned, the APIs ignoring the filters they do not support.
+func (c *piholeClient) GetQueries(ctx context.Context, filter queryFilter) ([]piholeQuery, error) {
+	// The queries of each detailed status are requested in turn from the v6 API
+	filters := []queryFilter{filter}
+	if filter.Status != "" && c.api.Version() >= 6 {
+		filters = nil
+		for _, detail := range queryStatusDetails(filter.Status) {
+			detailFilter := filter
+			detailFilter.Detail = detail
+			filters = append(filters, detailFilter)
+		}
+	}
+
+	var queries []piholeQuery
+	for _, filter := range filters {
+		matched := 0
+		err := c.api.GetQueries(ctx, filter, func(page []piholeQuery) bool {
+			for _, query := range page {
+				if filter.match(query) {
+					queries = append(queries, query)
+					matched++
+				}
+			}
+			return filter.Limit <= 0 || matched < filter.Limit
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(queries, func(i, j int) bool { return queries[i].Time.After(queries[j].Time) })
+	if filter.Limit > 0 && len(queries) > filter.Limit {
+		queries = queries[:filter.Limit]
+	}
+
+	return queries, nil
+}
+
+// unixTime returns the time of a Unix timestamp with fractional seconds.
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// v5QueryWindow bounds the queries requested from the v5 API without a start
+// time to those of the last day, which FTL keeps in memory.
+const v5QueryWindow = 24 * time.Hour
+
+// GetQueries sends the queries of a client, of a domain or of a time window to
+// page, which the v5 API returns at once. The API takes a single filter: a
+// time window given wins over the client, itself winning over the domain.
+func (a *v5API) GetQueries(ctx context.Context, filter queryFilter, page func([]piholeQuery) bool) error {
+	params := url.Values{"getAllQueries": {""}, "auth": {a.base.APIKey}}
+	window := !filter.From.IsZero() || !filter.Until.IsZero()
+	switch {
+	case !window && filter.Client != "":
+		params.Set("client", filter.Client)
+		if filter.Status == "blocked" {
+			params.Set("type", "blocked")
+		}
+	case !window && filter.Domain != "" && !strings.ContainsAny(filter.Domain, "*?[\\"):
+		params.Set("domain", filter.Domain)
+	default:
+		from, until := filter.From, filter.Until
+		if until.IsZero() {
+			until = time.Now()
+		}
+		if from.IsZero() {
+			from = until.Add(-v5QueryWindow)
+		}
+		params.Set("from", strconv.FormatInt(from.Unix(), 10))
+		params.Set("until", strconv.FormatInt(until.Unix(), 10))
+	}
+
+	// Each query is an array of timestamp, type, domain, client, status,
+	// DNSSEC status, reply type, reply time, CNAME domain, regex ID and
+	// upstream, holding strings or numbers depending on the version
+	var answer struct {
+		Data [][]interface{} `json:"data"`
+	}
+	if err := a.getJSON(ctx, params, &answer); err != nil {
+		return err
+	}
+
+	field := func(row []interface{}, i int) string {
+		if i >= len(row) || row[i] == nil {
+			return ""
+		}
+		return fmt.Sprint(row[i])
+	}
+
+	queries := make([]piholeQuery, 0, len(answer.Data))
+	for _, row := range answer.Data {
+		timestamp, err := strconv.ParseFloat(field(row, 0), 64)
+		if err != nil {
+			return fmt.Errorf("unexpected query timestamp %q", field(row, 0))
+		}
+		detail := "UNKNOWN"
+		if status, err := strconv.Atoi(field(row, 4)); err == nil && status >= 0 && status < len(queryStatusNames) {
+			detail = queryStatusNames[status]
+		}
+		query := piholeQuery{
+			Time:     unixTime(timestamp),
+			Type:     field(row, 1),
+			Domain:   field(row, 2),
+			Client:   field(row, 3),
+			Detail:   detail,
+			Upstream: field(row, 10),
+		}
+		// The API reports the name of known clients, so the address of the
+		// client selected by the API is restored
+		if params.Has("client") && query.Client != filter.Client && net.ParseIP(filter.Client) != nil {
+			query.Client, query.ClientName = filter.Client, query.Client
+		}
+		queries = append(queries, query)
+	}
+	page(queries)
+
+	return nil
+}
+
+// queryPageLength is the number of queries requested at once from the v6 API.
+const queryPageLength = 1000
+
+// GetQueries sends the queries selected by the filter to page, newest first,
+// one page of the v6 API at a time while page returns true. FTL matches the
+// * wildcards of domain patterns, but no other pattern, nor statuses other than
+// the detailed ones.
+func (a *v6API) GetQueries(ctx context.Context, filter queryFilter, page func([]piholeQuery) bool) error {
+	params := url.Values{"length": {strconv.Itoa(queryPageLength)}}
+	if !filter.From.IsZero() {
+		params.Set("from", strconv.FormatInt(filter.From.Unix(), 10))
+	}
+	if !filter.Until.IsZero() {
+		params.Set("until", strconv.FormatInt(filter.Until.Unix(), 10))
+	}
+	if filter.Client != "" {
+		if net.ParseIP(filter.Client) != nil {
+			params.Set("client_ip", filter.Client)
+		} else {
+			params.Set("client_name", filter.Client)
+		}
+	}
+	if filter.Domain != "" && !strings.ContainsAny(filter.Domain, "?[\\") {
+		params.Set("domain", strings.ToLower(filter.Domain))
+	}
+	if filter.Type != "" {
+		params.Set("type", strings.ToUpper(filter.Type))
+	}
+	if filter.Detail != "" {
+		params.Set("status", filter.Detail)
+	}
+
+	for start := 0; ; start += queryPageLength {
+		params.Set("start", strconv.Itoa(start))
+
+		var answer struct {
+			Queries []struct {
+				Time   float64 `json:"time"`
+				Type   string  `json:"type"`
+				Domain string  `json:"domain"`
+				Status string  `json:"status"`
+				Client struct {
+					IP   string `json:"ip"`
+					Name string `json:"name"`
+				} `json:"client"`
+				Upstream *string `json:"upstream"`
+			} `json:"queries"`
+			// Cursor pins the following pages to the queries of the first
+			Cursor json.Number `json:"cursor"`
+		}
+		if err := a.do(ctx, http.MethodGet, "/queries?"+params.Encode(), nil, &answer); err != nil {
+			return err
+		}
+		if answer.Cursor != "" {
+			params.Set("cursor", answer.Cursor.String())
+		}
+
+		queries := make([]piholeQuery, 0, len(answer.Queries))
+		for _, q := range answer.Queries {
+			query := piholeQuery{
+				Time:       unixTime(q.Time),
+				Type:       q.Type,
+				Domain:     q.Domain,
+				Client:     q.Client.IP,
+				ClientName: q.Client.Name,
+				Detail:     q.Status,
+			}
+			if q.Upstream != nil {
+				query.Upstream = *q.Upstream
+			}
+			queries = append(queries, query)
+		}
+
+		if !page(queries) || len(answer.Queries) < queryPageLength {
+			return nil
+		}
+	}
+}
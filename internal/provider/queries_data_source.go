@@ -0,0 +1,228 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &QueriesDataSource{}
+	_ datasource.DataSourceWithConfigure = &QueriesDataSource{}
+)
+
+// defaultQueriesLimit is the number of queries returned when limit is not set.
+const defaultQueriesLimit = 100
+
+// NewQueriesDataSource is a helper function to simplify the provider implementation.
+func NewQueriesDataSource() datasource.DataSource {
+	return &QueriesDataSource{}
+}
+
+// QueriesDataSource is the data source implementation.
+type QueriesDataSource struct {
+	client *piholeClient
+}
+
+// QueriesDataSourceModel maps the data source schema data.
+type QueriesDataSourceModel struct {
+	From    types.String `tfsdk:"from"`
+	Until   types.String `tfsdk:"until"`
+	Client  types.String `tfsdk:"client"`
+	Domain  types.String `tfsdk:"domain"`
+	Status  types.String `tfsdk:"status"`
+	Type    types.String `tfsdk:"type"`
+	Limit   types.Int64  `tfsdk:"limit"`
+	Queries []QueryModel `tfsdk:"queries"`
+}
+
+// QueryModel maps a query of the query log.
+type QueryModel struct {
+	Time         types.String `tfsdk:"time"`
+	Type         types.String `tfsdk:"type"`
+	Domain       types.String `tfsdk:"domain"`
+	Client       types.String `tfsdk:"client"`
+	Status       types.String `tfsdk:"status"`
+	StatusDetail types.String `tfsdk:"status_detail"`
+	Upstream     types.String `tfsdk:"upstream"`
+}
+
+// Metadata returns the data source type name.
+func (d *QueriesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_queries"
+}
+
+// Schema defines the schema for the data source.
+func (d *QueriesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Most recent queries of the Pihole query log, newest first, selected by the given filters.",
+		Attributes: map[string]schema.Attribute{
+			"from": schema.StringAttribute{
+				Description: "RFC 3339 timestamp of the oldest queries returned, such as timeadd(timestamp(), \"-15m\"). Pihole v5 " +
+					"returns the queries of the last day when unset, except when filtering on client or on a domain without pattern.",
+				Optional: true,
+			},
+			"until": schema.StringAttribute{
+				Description: "RFC 3339 timestamp of the newest queries returned.",
+				Optional:    true,
+			},
+			"client": schema.StringAttribute{
+				Description: "IP address or host name of the client sending the queries.",
+				Optional:    true,
+			},
+			"domain": schema.StringAttribute{
+				Description: "Domain queried, or a pattern such as *.example.com.",
+				Optional:    true,
+			},
+			"status": schema.StringAttribute{
+				Description: "Status of the queries, among blocked, forwarded and cached.",
+				Optional:    true,
+				Validators: []validator.String{
+					oneOfValidator{values: queryStatuses},
+				},
+			},
+			"type": schema.StringAttribute{
+				Description: "Type of the queries, such as A, AAAA or PTR.",
+				Optional:    true,
+			},
+			"limit": schema.Int64Attribute{
+				Description: fmt.Sprintf("Maximum number of queries returned. Defaults to %d.", defaultQueriesLimit),
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64RangeValidator{min: 1, max: 100000},
+				},
+			},
+			"queries": schema.ListNestedAttribute{
+				Description: "Queries selected, newest first.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"time": schema.StringAttribute{
+							Description: "RFC 3339 timestamp of the query.",
+							Computed:    true,
+						},
+						"type": schema.StringAttribute{
+							Description: "Type of the query, such as A.",
+							Computed:    true,
+						},
+						"domain": schema.StringAttribute{
+							Description: "Domain queried.",
+							Computed:    true,
+						},
+						"client": schema.StringAttribute{
+							Description: "IP address of the client, or its host name on Pihole v5.",
+							Computed:    true,
+						},
+						"status": schema.StringAttribute{
+							Description: "Status of the query: blocked, forwarded, cached or unknown.",
+							Computed:    true,
+						},
+						"status_detail": schema.StringAttribute{
+							Description: "Detailed status of the query, such as GRAVITY, REGEX or DENYLIST for blocked queries.",
+							Computed:    true,
+						},
+						"upstream": schema.StringAttribute{
+							Description: "Upstream server answering forwarded queries.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *QueriesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*piholeClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *piholeClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+// Read queries the query log.
+func (d *QueriesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state QueriesDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter := queryFilter{
+		Client: state.Client.ValueString(),
+		Domain: state.Domain.ValueString(),
+		Status: state.Status.ValueString(),
+		Type:   state.Type.ValueString(),
+		Limit:  defaultQueriesLimit,
+	}
+	if !state.Limit.IsNull() {
+		filter.Limit = int(state.Limit.ValueInt64())
+	}
+	for name, value := range map[string]types.String{"from": state.From, "until": state.Until} {
+		if value.IsNull() {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(name),
+				"Invalid Timestamp",
+				fmt.Sprintf("%q is not an RFC 3339 timestamp, such as 2024-01-02T15:04:05Z.", value.ValueString()),
+			)
+			continue
+		}
+		if name == "from" {
+			filter.From = parsed
+		} else {
+			filter.Until = parsed
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	queries, err := d.client.GetQueries(ctx, filter)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Pihole Queries",
+			"Could not read the query log: "+err.Error(),
+		)
+		return
+	}
+
+	state.Queries = make([]QueryModel, 0, len(queries))
+	for _, query := range queries {
+		state.Queries = append(state.Queries, QueryModel{
+			Time:         types.StringValue(query.Time.UTC().Format(time.RFC3339)),
+			Type:         types.StringValue(query.Type),
+			Domain:       types.StringValue(query.Domain),
+			Client:       types.StringValue(query.Client),
+			Status:       types.StringValue(queryStatus(query.Detail)),
+			StatusDetail: types.StringValue(query.Detail),
+			Upstream:     types.StringValue(query.Upstream),
+		})
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
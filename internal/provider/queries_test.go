@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeQueries returns a query log of a client querying example.com, blocked,
+// then forwarded twice, and another client querying ads.example.net.
+func fakeQueries(now time.Time) []piholeQuery {
+	return []piholeQuery{
+		{Time: now.Add(-time.Hour), Type: "A", Domain: "example.com", Client: "192.168.1.10", ClientName: "laptop.lan", Detail: "GRAVITY"},
+		{Time: now.Add(-30 * time.Minute), Type: "AAAA", Domain: "www.example.com", Client: "192.168.1.10", ClientName: "laptop.lan", Detail: "FORWARDED", Upstream: "9.9.9.9#53"},
+		{Time: now.Add(-20 * time.Minute), Type: "A", Domain: "ads.example.net", Client: "192.168.1.20", Detail: "REGEX"},
+		{Time: now.Add(-10 * time.Minute), Type: "A", Domain: "example.com", Client: "192.168.1.10", ClientName: "laptop.lan", Detail: "CACHE"},
+	}
+}
+
+func TestQueryStatus(t *testing.T) {
+	for detail, status := range map[string]string{
+		"GRAVITY": "blocked", "DENYLIST_CNAME": "blocked", "EXTERNAL_BLOCKED_NULL": "blocked",
+		"FORWARDED": "forwarded", "RETRIED_DNSSEC": "forwarded",
+		"CACHE": "cached", "CACHE_STALE": "cached",
+		"DBBUSY": "blocked", "SPECIAL_DOMAIN": "blocked", "EXTERNAL_BLOCKED_EDE15": "blocked",
+		"UNKNOWN": "unknown", "": "unknown", "NEW_STATUS": "unknown",
+	} {
+		if got := queryStatus(detail); got != status {
+			t.Errorf("%s: expected %s, got %s", detail, status, got)
+		}
+	}
+}
+
+func TestClientQueries(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	tests := map[string]struct {
+		filter  queryFilter
+		domains []string
+	}{
+		"all": {
+			filter:  queryFilter{},
+			domains: []string{"example.com", "ads.example.net", "www.example.com", "example.com"},
+		},
+		"window": {
+			filter:  queryFilter{From: now.Add(-40 * time.Minute), Until: now.Add(-15 * time.Minute)},
+			domains: []string{"ads.example.net", "www.example.com"},
+		},
+		"client": {
+			filter:  queryFilter{Client: "laptop.lan", Status: "blocked"},
+			domains: []string{"example.com"},
+		},
+		"client address": {
+			filter:  queryFilter{Client: "192.168.1.20"},
+			domains: []string{"ads.example.net"},
+		},
+		"pattern": {
+			filter:  queryFilter{Domain: "*.example.*", Type: "a"},
+			domains: []string{"ads.example.net"},
+		},
+		"limit": {
+			filter:  queryFilter{Client: "laptop.lan", Limit: 2},
+			domains: []string{"example.com", "www.example.com"},
+		},
+	}
+
+	for _, v6 := range []bool{false, true} {
+		fake := newFakePihole(t)
+		fake.v6 = v6
+		fake.queries = fakeQueries(now)
+		client := fake.client()
+
+		for name, test := range tests {
+			queries, err := client.GetQueries(context.Background(), test.filter)
+			if err != nil {
+				t.Fatalf("v6=%t %s: %v", v6, name, err)
+			}
+			var domains []string
+			for _, query := range queries {
+				domains = append(domains, query.Domain)
+			}
+			if len(domains) != len(test.domains) {
+				t.Fatalf("v6=%t %s: expected %v, got %v", v6, name, test.domains, domains)
+			}
+			for i := range domains {
+				if domains[i] != test.domains[i] {
+					t.Fatalf("v6=%t %s: expected %v, got %v", v6, name, test.domains, domains)
+				}
+			}
+		}
+	}
+}
+
+func TestClientQueriesV5Filters(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	fake := newFakePihole(t)
+	fake.queries = append([]piholeQuery{
+		{Time: now.Add(-48 * time.Hour), Type: "A", Domain: "example.com", Client: "192.168.1.10", Detail: "FORWARDED"},
+	}, fakeQueries(now)...)
+	client := fake.client()
+
+	tests := []struct {
+		filter  queryFilter
+		call    string
+		queries int
+	}{
+		// The query log is bounded to the last day without a start time
+		{queryFilter{}, "queries/from", 4},
+		{queryFilter{Client: "192.168.1.10"}, "queries/client", 4},
+		{queryFilter{Domain: "EXAMPLE.com"}, "queries/domain", 3},
+		{queryFilter{Domain: "*.example.net"}, "queries/from", 1},
+		// The time window wins over the client
+		{queryFilter{Client: "laptop.lan", From: now.Add(-72 * time.Hour)}, "queries/from", 3},
+	}
+	for _, test := range tests {
+		before := fake.count(test.call)
+		queries, err := client.GetQueries(context.Background(), test.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calls := fake.count(test.call) - before; calls != 1 {
+			t.Errorf("%+v: expected a %s request, got %d", test.filter, test.call, calls)
+		}
+		if len(queries) != test.queries {
+			t.Errorf("%+v: expected %d queries, got %d", test.filter, test.queries, len(queries))
+		}
+	}
+}
+
+func TestClientQueriesV6Filters(t *testing.T) {
+	fake := newFakePiholeV6(t)
+	fake.queries = fakeQueries(time.Now().Truncate(time.Second))
+	client := fake.client()
+
+	tests := []struct {
+		filter  queryFilter
+		call    string
+		calls   int
+		queries int
+	}{
+		{queryFilter{}, "queries/all", 1, 4},
+		{queryFilter{Client: "192.168.1.10", Type: "a"}, "queries/client_ip+type", 1, 2},
+		{queryFilter{Client: "laptop.lan", Domain: "EXAMPLE.com"}, "queries/client_name+domain", 1, 2},
+		{queryFilter{Domain: "*.example.net"}, "queries/domain", 1, 1},
+		// Patterns other than * wildcards are only matched by the client
+		{queryFilter{Domain: "?ww.example.com"}, "queries/all", 1, 1},
+		// One request for each detailed status
+		{queryFilter{Status: "blocked"}, "queries/status", len(queryStatusDetails("blocked")), 2},
+	}
+	for _, test := range tests {
+		before := fake.count(test.call)
+		queries, err := client.GetQueries(context.Background(), test.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calls := fake.count(test.call) - before; calls != test.calls {
+			t.Errorf("%+v: expected %d %s requests, got %d", test.filter, test.calls, test.call, calls)
+		}
+		if len(queries) != test.queries {
+			t.Errorf("%+v: expected %d queries, got %d", test.filter, test.queries, len(queries))
+		}
+	}
+}
+
+func TestClientQueriesPages(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	fake := newFakePiholeV6(t)
+	for i := 0; i < 2*queryPageLength+10; i++ {
+		detail := "FORWARDED"
+		if i%100 == 0 {
+			detail = "GRAVITY"
+		}
+		fake.queries = append(fake.queries, piholeQuery{Time: now.Add(time.Duration(i) * time.Second), Type: "A", Domain: "example.com", Client: "192.168.1.10", Detail: detail})
+	}
+
+	queries, err := fake.client().GetQueries(context.Background(), queryFilter{Status: "blocked", Limit: 25})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 21 {
+		t.Fatalf("expected the 21 blocked queries of all pages, got %d", len(queries))
+	}
+	if !queries[0].Time.After(queries[20].Time) {
+		t.Fatal("expected the newest queries first")
+	}
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &RegexForDomainFunction{}
+
+// NewRegexForDomainFunction is a helper function to simplify the provider implementation.
+func NewRegexForDomainFunction() function.Function {
+	return &RegexForDomainFunction{}
+}
+
+// RegexForDomainFunction is the function implementation.
+type RegexForDomainFunction struct{}
+
+// Metadata returns the function name.
+func (f *RegexForDomainFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "regex_for_domain"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *RegexForDomainFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Returns the Pihole regex matching a domain",
+		Description: "Returns the regex of Pihole domain lists matching a domain, such as ^example\\.com$, or " +
+			"(\\.|^)example\\.com$ matching its subdomains too, as the web interface does for wildcard entries.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "domain",
+				Description: "Domain to match, such as example.com.",
+			},
+			function.BoolParameter{
+				Name:        "include_subdomains",
+				Description: "Whether to match the subdomains of the domain too.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run returns the regex.
+func (f *RegexForDomainFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var domain string
+	var subdomains bool
+	resp.Error = req.Arguments.Get(ctx, &domain, &subdomains)
+	if resp.Error != nil {
+		return
+	}
+
+	if !isValidHostname(strings.TrimSuffix(domain, ".")) {
+		resp.Error = function.NewArgumentFuncError(0, domain+" is not a valid RFC 1123 hostname")
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, domainRegex(domain, subdomains))
+}
+
+// domainRegex returns the regex of Pihole domain lists matching domain,
+// and its subdomains when subdomains is set, such as (\.|^)example\.com$.
+func domainRegex(domain string, subdomains bool) string {
+	quoted := regexp.QuoteMeta(strings.ToLower(strings.TrimSuffix(domain, ".")))
+	if subdomains {
+		return `(\.|^)` + quoted + "$"
+	}
+
+	return "^" + quoted + "$"
+}
@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// defaultMaxRetries is the number of retries when max_retries is not set.
+const defaultMaxRetries = 3
+
+// Bounds of the exponential backoff between two attempts. Variables so tests
+// do not have to wait.
+var (
+	retryWaitMin = 500 * time.Millisecond
+	retryWaitMax = 15 * time.Second
+)
+
+// transientError marks a failure worth retrying: the Pihole API could not be
+// reached or answered with a server error, typically while FTL restarts.
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+// isTransient reports whether err, or any error it wraps, is a transientError.
+func isTransient(err error) bool {
+	var transient *transientError
+	return errors.As(err, &transient)
+}
+
+// retryBackoff returns the wait before the retry following the given attempt,
+// starting at 0. The wait doubles on each attempt up to retryWaitMax, and a
+// random jitter spreads the retries of resources applied in parallel.
+func retryBackoff(attempt int) time.Duration {
+	wait := retryWaitMax
+	if attempt < 32 && retryWaitMin<<attempt < retryWaitMax {
+		wait = retryWaitMin << attempt
+	}
+
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// sleepContext waits for the given duration, or until the context is done.
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isIdempotentRequest reports whether a request can be sent again without
+// checking its outcome first. The v5 API does every call with GET, so the
+// action parameter tells reads and writes apart. A v6 login can be repeated
+// too, as it opens a new session at worst.
+func isIdempotentRequest(req *http.Request) bool {
+	switch {
+	case req.Method == http.MethodGet, req.Method == http.MethodHead, req.Method == http.MethodOptions:
+	case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/auth"):
+		return true
+	default:
+		return false
+	}
+
+	switch req.URL.Query().Get("action") {
+	case "add", "delete":
+		return false
+	}
+
+	return true
+}
+
+// longRequestKey marks the context of requests allowed to outlast the request
+// timeout.
+type longRequestKey struct{}
+
+// withoutRequestTimeout returns a context whose requests are only bounded by
+// its own deadline, such as a gravity update downloading every adlist.
+func withoutRequestTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, longRequestKey{}, true)
+}
+
+// retryTransport sends each attempt through an inner HTTP client, so the
+// request timeout applies to every attempt rather than to all of them.
+// Idempotent requests are retried with backoff on transient failures, other
+// requests fail with a transientError for the caller to check and retry.
+type retryTransport struct {
+	client     *http.Client
+	maxRetries int
+}
+
+// newRetryClient wraps the HTTP client so transient failures are retried up to
+// maxRetries times.
+func newRetryClient(client *http.Client, maxRetries int) *http.Client {
+	return &http.Client{
+		Transport: &retryTransport{
+			client:     client,
+			maxRetries: maxRetries,
+		},
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	idempotent := isIdempotentRequest(req)
+
+	client := t.client
+	if long, _ := req.Context().Value(longRequestKey{}).(bool); long {
+		unbounded := *t.client
+		unbounded.Timeout = 0
+		client = &unbounded
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		res, err := client.Do(req)
+
+		// The outer client reports the URL, no need to repeat it
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		failure := transientFailure(req, res, err)
+		if failure == nil {
+			return res, err
+		}
+
+		wait := retryBackoff(attempt)
+		if res != nil {
+			if after, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && after > 0 {
+				wait = time.Duration(after) * time.Second
+			}
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		if !idempotent || attempt >= t.maxRetries {
+			return nil, &transientError{err: failure}
+		}
+
+		tflog.Debug(req.Context(), "Retrying Pihole API request", map[string]interface{}{
+			"endpoint": redactURL(req.URL),
+			"attempt":  attempt + 1,
+			"wait":     wait.String(),
+			"error":    failure.Error(),
+		})
+
+		if err := sleepContext(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// transientFailure returns the reason to retry a request, or nil when its
+// outcome is final: a success, a client error, or an error retrying cannot fix.
+func transientFailure(req *http.Request, res *http.Response, err error) error {
+	if err != nil {
+		// The caller gave up, or the server certificate is not trusted
+		if req.Context().Err() != nil {
+			return nil
+		}
+		var authorityErr x509.UnknownAuthorityError
+		var hostnameErr x509.HostnameError
+		var invalidErr x509.CertificateInvalidError
+		if errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+			return nil
+		}
+		return err
+	}
+
+	if res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("pihole API answered %s", res.Status)
+	}
+
+	return nil
+}
+
+// retryWrite runs a non-idempotent write against the Pihole API. A transient
+// failure does not tell whether the write reached Pihole, so applied is asked
+// whether the change is already in place before the write is tried again.
+func (c *piholeClient) retryWrite(ctx context.Context, write func() error, applied func() (bool, error)) error {
+	err := write()
+
+	for attempt := 0; err != nil && isTransient(err) && attempt < c.maxRetries; attempt++ {
+		if err := sleepContext(ctx, retryBackoff(attempt)); err != nil {
+			return err
+		}
+
+		done, checkErr := applied()
+		switch {
+		case checkErr != nil && isTransient(checkErr):
+			// Still unknown, check again on the next attempt
+			continue
+		case checkErr != nil:
+			return checkErr
+		case done:
+			return nil
+		}
+
+		err = write()
+	}
+
+	return err
+}
@@ -0,0 +1,191 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	pihole "github.com/NicoFgrx/pihole-api-go/api"
+)
+
+// fastRetries shortens the backoff for the duration of the test.
+func fastRetries(t *testing.T) {
+	t.Helper()
+
+	waitMin, waitMax := retryWaitMin, retryWaitMax
+	retryWaitMin, retryWaitMax = time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() {
+		retryWaitMin, retryWaitMax = waitMin, waitMax
+	})
+}
+
+func TestRetryBackoff(t *testing.T) {
+	for attempt := 0; attempt < 40; attempt++ {
+		wait := retryBackoff(attempt)
+		if wait <= 0 || wait > retryWaitMax {
+			t.Fatalf("attempt %d: backoff %s out of bounds", attempt, wait)
+		}
+	}
+}
+
+func TestRetryReadAfterServerErrors(t *testing.T) {
+	fastRetries(t)
+
+	fake := newFakePihole(t)
+	failures := 2
+	fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return true
+		}
+		return false
+	}
+
+	if _, err := fake.client().GetAllCustomDNS(context.Background()); err != nil {
+		t.Fatalf("expected the read to succeed after retries, got: %s", err)
+	}
+	if got := fake.count("customdns/get"); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestRetryReadGivesUp(t *testing.T) {
+	fastRetries(t)
+
+	fake := newFakePihole(t)
+	fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		w.WriteHeader(http.StatusBadGateway)
+		return true
+	}
+
+	_, err := fake.client().GetAllCustomDNS(context.Background())
+	if !isTransient(err) {
+		t.Fatalf("expected a transient error, got: %v", err)
+	}
+	if got := fake.count("customdns/get"); got != defaultMaxRetries+1 {
+		t.Fatalf("expected %d attempts, got %d", defaultMaxRetries+1, got)
+	}
+}
+
+func TestRetryWriteAppliedBeforeFailure(t *testing.T) {
+	fastRetries(t)
+
+	// The record is created, but the answer is lost while FTL restarts
+	fake := newFakePihole(t)
+	fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Query().Get("action") == "add" {
+			fake.mu.Lock()
+			fake.dns = append(fake.dns, []string{r.URL.Query().Get("domain"), r.URL.Query().Get("ip")})
+			fake.mu.Unlock()
+			w.WriteHeader(http.StatusInternalServerError)
+			return true
+		}
+		return false
+	}
+
+	err := fake.client().AddCustomDNS(context.Background(), &pihole.DNSRecordParams{Domain: "test.example.com", IP: "1.2.3.4"})
+	if err != nil {
+		t.Fatalf("expected the add to be found applied, got: %s", err)
+	}
+	if got := fake.count("customdns/add"); got != 1 {
+		t.Fatalf("expected the add not to be repeated, got %d attempts", got)
+	}
+}
+
+func TestRetryWriteNotApplied(t *testing.T) {
+	fastRetries(t)
+
+	fake := newFakePihole(t)
+	failures := 1
+	fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Query().Get("action") == "add" && failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return true
+		}
+		return false
+	}
+
+	err := fake.client().AddCustomDNS(context.Background(), &pihole.DNSRecordParams{Domain: "test.example.com", IP: "1.2.3.4"})
+	if err != nil {
+		t.Fatalf("expected the add to succeed after a retry, got: %s", err)
+	}
+	if got := fake.count("customdns/add"); got != 2 {
+		t.Fatalf("expected 2 add attempts, got %d", got)
+	}
+	if got := fake.count("customdns/get"); got != 1 {
+		t.Fatalf("expected 1 check before the retry, got %d", got)
+	}
+}
+
+func TestRetryWriteFinalError(t *testing.T) {
+	fastRetries(t)
+
+	fake := newFakePihole(t)
+	client := fake.client()
+	params := &pihole.DNSRecordParams{Domain: "test.example.com", IP: "1.2.3.4"}
+
+	if err := client.AddCustomDNS(context.Background(), params); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AddCustomDNS(context.Background(), params); err == nil || isTransient(err) {
+		t.Fatalf("expected a final error for a duplicate record, got: %v", err)
+	}
+	if got := fake.count("customdns/add"); got != 2 {
+		t.Fatalf("expected the duplicate add not to be retried, got %d attempts", got)
+	}
+}
+
+func TestRetryWriteReadBackIgnoresCase(t *testing.T) {
+	fake := newFakePihole(t)
+	client := fake.client()
+
+	if err := client.AddCustomDNS(context.Background(), &pihole.DNSRecordParams{Domain: "NAS.example.com", IP: "10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AddCustomCNAME(context.Background(), &pihole.CNAMERecordParams{Domain: "Files.example.com", Target: "nas.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The record found applied by a retried write is read back
+	if _, err := client.GetCustomDNS(context.Background(), "nas.example.com"); err != nil {
+		t.Errorf("expected the record to be found whatever its case, got: %s", err)
+	}
+	if _, err := client.GetCustomCNAME(context.Background(), "files.example.com"); err != nil {
+		t.Errorf("expected the CNAME to be found whatever its case, got: %s", err)
+	}
+}
+
+func TestRetryLoginDuringRestart(t *testing.T) {
+	fastRetries(t)
+
+	// FTL drops the connection while it stops, then answers 503 until its API
+	// is ready
+	fake := newFakePiholeV6(t)
+	failures := 0
+	fake.intercept = func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/api/auth" || failures >= 2 {
+			return false
+		}
+		failures++
+		if failures == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+			return true
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return true
+	}
+
+	if _, err := fake.client().GetAllCustomDNS(context.Background()); err != nil {
+		t.Fatalf("expected the login to be retried, got: %s", err)
+	}
+	if got := fake.count("auth/login"); got != 3 {
+		t.Fatalf("expected 3 login attempts, got %d", got)
+	}
+}
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &ReverseNameFunction{}
+
+// NewReverseNameFunction is a helper function to simplify the provider implementation.
+func NewReverseNameFunction() function.Function {
+	return &ReverseNameFunction{}
+}
+
+// ReverseNameFunction is the function implementation.
+type ReverseNameFunction struct{}
+
+// Metadata returns the function name.
+func (f *ReverseNameFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "reverse_name"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *ReverseNameFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Returns the reverse DNS name of an IP address",
+		Description: "Returns the name of the PTR record of an IP address, under in-addr.arpa for IPv4 addresses " +
+			"and ip6.arpa for IPv6 addresses, such as 10.1.168.192.in-addr.arpa for 192.168.1.10.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "ip",
+				Description: "IPv4 or IPv6 address.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run returns the reverse name of the IP address.
+func (f *ReverseNameFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var ip string
+	resp.Error = req.Arguments.Get(ctx, &ip)
+	if resp.Error != nil {
+		return
+	}
+
+	name, err := reverseName(ip)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, name)
+}
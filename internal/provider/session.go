@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// providerSessions are the v6 APIs configured by the provider process, whose
+// sessions are logged out by CloseSessions when the provider stops, so that
+// repeated runs do not exhaust webserver.api.max_sessions.
+var providerSessions struct {
+	mu   sync.Mutex
+	apis []*v6API
+}
+
+// trackSession records the API so that CloseSessions logs its session out.
+// APIs other than v6 have no session.
+func trackSession(api piholeAPI) {
+	if api, ok := api.(*v6API); ok {
+		providerSessions.mu.Lock()
+		defer providerSessions.mu.Unlock()
+
+		providerSessions.apis = append(providerSessions.apis, api)
+	}
+}
+
+// CloseSessions logs out the sessions opened by the provider. It is called
+// once the provider server stops.
+func CloseSessions(ctx context.Context) {
+	providerSessions.mu.Lock()
+	apis := providerSessions.apis
+	providerSessions.apis = nil
+	providerSessions.mu.Unlock()
+
+	for _, api := range apis {
+		if err := api.logout(ctx); err != nil {
+			// The session expires anyway
+			tflog.Warn(ctx, "Could not log the Pihole session out: "+err.Error())
+		}
+	}
+}
+
+// OpenSession opens a v6 API session of its own, distinct from the session
+// of the provider, and returns it with the API endpoint it is valid for.
+func (c *piholeClient) OpenSession(ctx context.Context) (v6Session, string, error) {
+	api, err := c.v6("pihole_session")
+	if err != nil {
+		return v6Session{}, "", err
+	}
+
+	session, err := api.openSession(ctx)
+
+	return session, api.endpoint, err
+}
+
+// RenewSession extends the validity of a session opened by OpenSession.
+func (c *piholeClient) RenewSession(ctx context.Context, sid string) (v6Session, error) {
+	api, err := c.v6("pihole_session")
+	if err != nil {
+		return v6Session{}, err
+	}
+
+	return api.renewSession(ctx, sid)
+}
+
+// CloseSession logs a session opened by OpenSession out.
+func (c *piholeClient) CloseSession(ctx context.Context, sid string) error {
+	api, err := c.v6("pihole_session")
+	if err != nil {
+		return err
+	}
+
+	return api.closeSession(ctx, sid)
+}
+
+// openSession logs in, without replacing the session of the client.
+func (a *v6API) openSession(ctx context.Context) (v6Session, error) {
+	var auth v6AuthResponse
+	if err := a.send(ctx, http.MethodPost, "/auth", "", map[string]string{"password": a.password}, &auth); err != nil {
+		return v6Session{}, err
+	}
+	if auth.Session == nil || !auth.Session.Valid {
+		return v6Session{}, &v6Error{Status: http.StatusUnauthorized, Key: "unauthorized", Message: "Pihole rejected the password"}
+	}
+
+	return *auth.Session, nil
+}
+
+// renewSession checks a session, which extends its validity.
+func (a *v6API) renewSession(ctx context.Context, sid string) (v6Session, error) {
+	var auth v6AuthResponse
+	if err := a.send(ctx, http.MethodGet, "/auth", sid, nil, &auth); err != nil {
+		return v6Session{}, err
+	}
+	if auth.Session == nil || !auth.Session.Valid {
+		return v6Session{}, &v6Error{Status: http.StatusUnauthorized, Key: "unauthorized", Message: "The session expired"}
+	}
+	auth.Session.SID = sid
+
+	return *auth.Session, nil
+}
+
+// closeSession logs a session out. Sessions without SID, opened when Pihole
+// has no password, have nothing to close.
+func (a *v6API) closeSession(ctx context.Context, sid string) error {
+	if sid == "" {
+		return nil
+	}
+
+	return a.send(ctx, http.MethodDelete, "/auth", sid, nil, nil)
+}
@@ -0,0 +1,184 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ ephemeral.EphemeralResource              = &SessionEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithConfigure = &SessionEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithRenew     = &SessionEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithClose     = &SessionEphemeralResource{}
+)
+
+// sessionPrivateKey is the private data key holding the SID to renew and close.
+const sessionPrivateKey = "sid"
+
+// NewSessionEphemeralResource is a helper function to simplify the provider implementation.
+func NewSessionEphemeralResource() ephemeral.EphemeralResource {
+	return &SessionEphemeralResource{}
+}
+
+// SessionEphemeralResource is the ephemeral resource implementation.
+type SessionEphemeralResource struct {
+	client *piholeClient
+}
+
+// SessionEphemeralResourceModel maps the ephemeral resource schema data.
+type SessionEphemeralResourceModel struct {
+	URL      types.String `tfsdk:"url"`
+	SID      types.String `tfsdk:"sid"`
+	CSRF     types.String `tfsdk:"csrf"`
+	Validity types.Int64  `tfsdk:"validity"`
+}
+
+// Metadata returns the ephemeral resource type name.
+func (r *SessionEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_session"
+}
+
+// Schema defines the schema for the ephemeral resource.
+func (r *SessionEphemeralResource) Schema(_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Pihole API session opened for the duration of a Terraform run, to call the endpoints the " +
+			"provider does not cover. The session is renewed while in use, logged out at the end of the run, and " +
+			"never stored in the plan or state. Requires Pihole v6 and Terraform 1.10 or later.",
+		Attributes: map[string]schema.Attribute{
+			"url": schema.StringAttribute{
+				Description: "URL of the API the session is valid for, such as http://pi.hole/api.",
+				Computed:    true,
+			},
+			"sid": schema.StringAttribute{
+				Description: "Session ID, sent in the X-FTL-SID header. Empty when Pihole has no password.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"csrf": schema.StringAttribute{
+				Description: "CSRF token, sent in the X-FTL-CSRF header along with the session cookie.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"validity": schema.Int64Attribute{
+				Description: "Seconds the session remains valid without being used.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the ephemeral resource.
+func (r *SessionEphemeralResource) Configure(_ context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*piholeClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *piholeClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// renewAt returns when to renew a session, halfway through its validity.
+func renewAt(session v6Session) time.Time {
+	return time.Now().Add(time.Duration(session.Validity) * time.Second / 2)
+}
+
+// Open logs in a new session.
+func (r *SessionEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	session, endpoint, err := r.client.OpenSession(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Opening Pihole Session",
+			"Could not log in to Pihole: "+err.Error(),
+		)
+		return
+	}
+
+	sid, err := json.Marshal(session.SID)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Opening Pihole Session", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, sessionPrivateKey, sid)...)
+	if session.Validity > 0 {
+		resp.RenewAt = renewAt(session)
+	}
+
+	result := SessionEphemeralResourceModel{
+		URL:      types.StringValue(endpoint),
+		SID:      types.StringValue(session.SID),
+		CSRF:     types.StringValue(session.CSRF),
+		Validity: types.Int64Value(int64(session.Validity)),
+	}
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &result)...)
+}
+
+// sid returns the SID kept in the private data.
+func (r *SessionEphemeralResource) sid(ctx context.Context, private interface {
+	GetKey(context.Context, string) ([]byte, diag.Diagnostics)
+}) (string, diag.Diagnostics) {
+	data, diags := private.GetKey(ctx, sessionPrivateKey)
+	if diags.HasError() || data == nil {
+		return "", diags
+	}
+
+	var sid string
+	if err := json.Unmarshal(data, &sid); err != nil {
+		diags.AddError("Error Reading Pihole Session", err.Error())
+	}
+
+	return sid, diags
+}
+
+// Renew extends the validity of the session while Terraform uses it.
+func (r *SessionEphemeralResource) Renew(ctx context.Context, req ephemeral.RenewRequest, resp *ephemeral.RenewResponse) {
+	sid, diags := r.sid(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || sid == "" {
+		return
+	}
+
+	session, err := r.client.RenewSession(ctx, sid)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Renewing Pihole Session",
+			"Could not renew the Pihole session: "+err.Error(),
+		)
+		return
+	}
+	if session.Validity > 0 {
+		resp.RenewAt = renewAt(session)
+	}
+}
+
+// Close logs the session out.
+func (r *SessionEphemeralResource) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	sid, diags := r.sid(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.CloseSession(ctx, sid); err != nil {
+		// The session expires anyway
+		tflog.Warn(ctx, "Could not log the Pihole session out: "+err.Error())
+	}
+}
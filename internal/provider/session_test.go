@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestClientSession(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	client := fake.client()
+
+	session, endpoint, err := client.OpenSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.SID != fakePiholeSID || session.CSRF != fakePiholeCSRF || session.Validity != 1800 {
+		t.Fatalf("unexpected session %+v", session)
+	}
+	if !strings.HasSuffix(endpoint, "/api") {
+		t.Fatalf("expected the API endpoint, got %s", endpoint)
+	}
+
+	renewed, err := client.RenewSession(ctx, session.SID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renewed.SID != session.SID || renewed.Validity != 1800 {
+		t.Fatalf("unexpected renewed session %+v", renewed)
+	}
+
+	if err := client.CloseSession(ctx, session.SID); err != nil {
+		t.Fatal(err)
+	}
+	if calls := fake.count("auth/delete"); calls != 1 {
+		t.Fatalf("expected the session to be logged out once, got %d", calls)
+	}
+
+	if _, err := client.RenewSession(ctx, "expired"); err == nil {
+		t.Fatal("expected an error renewing an unknown session")
+	}
+
+	if _, _, err := newFakePihole(t).client().OpenSession(ctx); err == nil {
+		t.Fatal("expected an error on Pihole v5")
+	}
+}
+
+func TestCloseSessions(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	client := fake.client()
+	trackSession(client.api)
+	trackSession(newFakePihole(t).client().api)
+
+	if _, err := client.GetAllCustomDNS(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	CloseSessions(ctx)
+	CloseSessions(ctx)
+	if calls := fake.count("auth/delete"); calls != 1 {
+		t.Fatalf("expected the provider session to be logged out once, got %d", calls)
+	}
+
+	// The client logs in again when used after its session was closed
+	if _, err := client.GetAllCustomDNS(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"sort"
+)
+
+// teleporterSections maps the sections of a Teleporter archive users can
+// restore to the import flags of the v6 API.
+var teleporterSections = map[string]func(flags *teleporterImport){
+	"adlists": func(flags *teleporterImport) {
+		flags.Gravity.Adlist, flags.Gravity.AdlistByGroup = true, true
+	},
+	"domains": func(flags *teleporterImport) {
+		flags.Gravity.Domainlist, flags.Gravity.DomainlistByGroup = true, true
+	},
+	"groups": func(flags *teleporterImport) {
+		flags.Gravity.Group = true
+	},
+	"clients": func(flags *teleporterImport) {
+		flags.Gravity.Client, flags.Gravity.ClientByGroup = true, true
+	},
+	// The whole FTL configuration, pihole.toml, which also holds the local
+	// DNS records, the upstreams, the web password and the DHCP settings
+	"config": func(flags *teleporterImport) {
+		flags.Config = true
+	},
+	"dhcp_leases": func(flags *teleporterImport) {
+		flags.DHCPLeases = true
+	},
+}
+
+// teleporterSectionNames returns the sorted names of the Teleporter sections.
+func teleporterSectionNames() []string {
+	names := make([]string, 0, len(teleporterSections))
+	for name := range teleporterSections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// teleporterImport selects the parts of a Teleporter archive to import.
+type teleporterImport struct {
+	Config     bool `json:"config"`
+	DHCPLeases bool `json:"dhcp_leases"`
+	Gravity    struct {
+		Group             bool `json:"group"`
+		Adlist            bool `json:"adlist"`
+		AdlistByGroup     bool `json:"adlist_by_group"`
+		Domainlist        bool `json:"domainlist"`
+		DomainlistByGroup bool `json:"domainlist_by_group"`
+		Client            bool `json:"client"`
+		ClientByGroup     bool `json:"client_by_group"`
+	} `json:"gravity"`
+}
+
+// newTeleporterImport returns the import flags of the given sections, which
+// must be known.
+func newTeleporterImport(sections []string) teleporterImport {
+	var flags teleporterImport
+	for _, section := range sections {
+		teleporterSections[section](&flags)
+	}
+
+	return flags
+}
+
+// teleporterChecksum returns the hex encoded SHA-256 of an archive.
+func teleporterChecksum(archive []byte) string {
+	sum := sha256.Sum256(archive)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// downloadTeleporter returns a Teleporter archive of the Pihole configuration.
+func (a *v6API) downloadTeleporter(ctx context.Context) ([]byte, error) {
+	var archive []byte
+	if err := a.do(ctx, http.MethodGet, "/teleporter", nil, &archive); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// uploadTeleporter imports the selected parts of a Teleporter archive, and
+// returns the files Pihole restored.
+func (a *v6API) uploadTeleporter(ctx context.Context, archive []byte, flags teleporterImport) ([]string, error) {
+	selection, err := json.Marshal(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", "pihole-teleporter.zip")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Write(archive); err != nil {
+		return nil, err
+	}
+	if err := form.WriteField("import", string(selection)); err != nil {
+		return nil, err
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	var answer struct {
+		Files []string `json:"files"`
+	}
+	err = a.do(ctx, http.MethodPost, "/teleporter", rawBody{contentType: form.FormDataContentType(), data: body.Bytes()}, &answer)
+	if err != nil {
+		return nil, err
+	}
+
+	return answer.Files, nil
+}
+
+// DownloadTeleporter returns a Teleporter archive of the Pihole configuration.
+func (c *piholeClient) DownloadTeleporter(ctx context.Context) ([]byte, error) {
+	api, err := c.v6("Teleporter backups")
+	if err != nil {
+		return nil, err
+	}
+
+	return api.downloadTeleporter(ctx)
+}
+
+// RestoreTeleporter imports the given sections of a Teleporter archive, and
+// returns the files Pihole restored.
+func (c *piholeClient) RestoreTeleporter(ctx context.Context, archive []byte, sections []string) ([]string, error) {
+	api, err := c.v6("Teleporter restores")
+	if err != nil {
+		return nil, err
+	}
+
+	// Importing the archive again is harmless, so a restore whose outcome is
+	// unknown is simply sent again
+	var files []string
+	err = c.write(ctx,
+		func() (err error) {
+			files, err = api.uploadTeleporter(ctx, archive, newTeleporterImport(sections))
+			return err
+		},
+		func() (bool, error) { return false, nil },
+	)
+
+	return files, err
+}
@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &TeleporterBackupDataSource{}
+	_ datasource.DataSourceWithConfigure = &TeleporterBackupDataSource{}
+)
+
+// NewTeleporterBackupDataSource is a helper function to simplify the provider implementation.
+func NewTeleporterBackupDataSource() datasource.DataSource {
+	return &TeleporterBackupDataSource{}
+}
+
+// TeleporterBackupDataSource is the data source implementation.
+type TeleporterBackupDataSource struct {
+	client *piholeClient
+}
+
+// TeleporterBackupDataSourceModel maps the data source schema data.
+type TeleporterBackupDataSourceModel struct {
+	OutputPath    types.String `tfsdk:"output_path"`
+	ContentBase64 types.String `tfsdk:"content_base64"`
+	SHA256        types.String `tfsdk:"sha256"`
+	Size          types.Int64  `tfsdk:"size"`
+}
+
+// Metadata returns the data source type name.
+func (d *TeleporterBackupDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_teleporter_backup"
+}
+
+// Schema defines the schema for the data source.
+func (d *TeleporterBackupDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Teleporter archive of the Pihole configuration, downloaded on each read. Requires Pihole v6.",
+		Attributes: map[string]schema.Attribute{
+			"output_path": schema.StringAttribute{
+				Description: "Path the archive is written to, readable by the current user only. " +
+					"When set, the archive is kept out of the state and content_base64 is null.",
+				Optional: true,
+			},
+			"content_base64": schema.StringAttribute{
+				Description: "Base64 encoded archive, when output_path is not set.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"sha256": schema.StringAttribute{
+				Description: "Hex encoded SHA-256 checksum of the archive.",
+				Computed:    true,
+			},
+			"size": schema.Int64Attribute{
+				Description: "Size of the archive in bytes.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *TeleporterBackupDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*piholeClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *piholeClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+// Read downloads the archive.
+func (d *TeleporterBackupDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state TeleporterBackupDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	archive, err := d.client.DownloadTeleporter(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Downloading Pihole Teleporter Archive",
+			"Could not download the Teleporter archive: "+err.Error(),
+		)
+		return
+	}
+
+	state.SHA256 = types.StringValue(teleporterChecksum(archive))
+	state.Size = types.Int64Value(int64(len(archive)))
+	state.ContentBase64 = types.StringNull()
+
+	if outputPath := state.OutputPath.ValueString(); outputPath != "" {
+		if err := os.WriteFile(outputPath, archive, 0o600); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("output_path"),
+				"Error Writing Pihole Teleporter Archive",
+				"Could not write the Teleporter archive: "+err.Error(),
+			)
+			return
+		}
+	} else {
+		state.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(archive))
+	}
+
+	tflog.Debug(ctx, "Downloaded Pihole Teleporter archive", map[string]any{"sha256": state.SHA256.ValueString(), "size": len(archive)})
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
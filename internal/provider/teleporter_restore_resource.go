@@ -0,0 +1,275 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &TeleporterRestoreResource{}
+	_ resource.ResourceWithConfigure      = &TeleporterRestoreResource{}
+	_ resource.ResourceWithValidateConfig = &TeleporterRestoreResource{}
+	_ resource.ResourceWithModifyPlan     = &TeleporterRestoreResource{}
+)
+
+// NewTeleporterRestoreResource is a helper function to simplify the provider implementation.
+func NewTeleporterRestoreResource() resource.Resource {
+	return &TeleporterRestoreResource{}
+}
+
+// TeleporterRestoreResource is the resource implementation.
+type TeleporterRestoreResource struct {
+	client *piholeClient
+}
+
+// TeleporterRestoreResourceModel maps the resource schema data.
+type TeleporterRestoreResourceModel struct {
+	LastUpdated   types.String `tfsdk:"last_updated"`
+	ArchivePath   types.String `tfsdk:"archive_path"`
+	ArchiveBase64 types.String `tfsdk:"archive_base64"`
+	Sections      types.Set    `tfsdk:"sections"`
+	SHA256        types.String `tfsdk:"sha256"`
+	Files         types.List   `tfsdk:"files"`
+}
+
+// Metadata returns the resource type name.
+func (r *TeleporterRestoreResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_teleporter_restore"
+}
+
+// Schema defines the schema for the resource.
+func (r *TeleporterRestoreResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	sections := make([]attr.Value, 0, len(teleporterSections))
+	for _, name := range teleporterSectionNames() {
+		sections = append(sections, types.StringValue(name))
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Restores a Teleporter archive into Pihole. The archive is restored again when its content or the " +
+			"sections change. Destroying the resource leaves the restored configuration in place. Requires Pihole v6.",
+		Attributes: map[string]schema.Attribute{
+			"last_updated": schema.StringAttribute{
+				Description: "Timestamp of the last Terraform update of the restore.",
+				Computed:    true,
+			},
+			"archive_path": schema.StringAttribute{
+				Description: "Path of the archive to restore. Exactly one of archive_path and archive_base64 must be set.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"archive_base64": schema.StringAttribute{
+				Description: "Base64 encoded archive to restore, such as the content_base64 of a pihole_teleporter_backup.",
+				Optional:    true,
+				Sensitive:   true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"sections": schema.SetAttribute{
+				Description: "Sections of the archive to restore, among adlists, clients, config, dhcp_leases, domains and " +
+					"groups. config restores the whole FTL configuration, pihole.toml, replacing the local DNS records but " +
+					"also the upstreams, the web password, the DHCP settings and every other setting. Defaults to all sections.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Computed:    true,
+				Default:     setdefault.StaticValue(types.SetValueMust(types.StringType, sections)),
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.Set{
+					oneOfValidator{values: teleporterSectionNames()},
+				},
+			},
+			"sha256": schema.StringAttribute{
+				Description: "Hex encoded SHA-256 checksum of the restored archive.",
+				Computed:    true,
+			},
+			"files": schema.ListAttribute{
+				Description: "Files Pihole restored from the archive.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *TeleporterRestoreResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*piholeClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *piholeClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+// ValidateConfig checks that a single archive is given.
+func (r *TeleporterRestoreResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config TeleporterRestoreResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.ArchivePath.IsUnknown() || config.ArchiveBase64.IsUnknown() {
+		return
+	}
+
+	if config.ArchivePath.IsNull() == config.ArchiveBase64.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("archive_path"),
+			"Invalid Teleporter Archive",
+			"Exactly one of archive_path and archive_base64 must be set.",
+		)
+	}
+}
+
+// ModifyPlan plans the checksum of the archive, so that a new archive at the
+// same path is restored again.
+func (r *TeleporterRestoreResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan TeleporterRestoreResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.ArchivePath.IsUnknown() || plan.ArchiveBase64.IsUnknown() {
+		return
+	}
+
+	archive, err := plan.archive()
+	if err != nil {
+		// Files created during the apply are checked by Create
+		if plan.ArchivePath.IsNull() || !errors.Is(err, os.ErrNotExist) {
+			resp.Diagnostics.AddAttributeError(plan.archiveAttribute(), "Invalid Teleporter Archive", err.Error())
+		}
+		return
+	}
+	checksum := types.StringValue(teleporterChecksum(archive))
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("sha256"), checksum)...)
+
+	var state TeleporterRestoreResourceModel
+	if req.State.Raw.IsNull() {
+		return
+	}
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if !state.SHA256.Equal(checksum) {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("sha256"))
+	}
+}
+
+// archive returns the content of the archive to restore.
+func (m TeleporterRestoreResourceModel) archive() ([]byte, error) {
+	if !m.ArchivePath.IsNull() {
+		return os.ReadFile(m.ArchivePath.ValueString())
+	}
+
+	archive, err := base64.StdEncoding.DecodeString(m.ArchiveBase64.ValueString())
+	if err != nil {
+		return nil, fmt.Errorf("archive_base64 is not base64 encoded: %w", err)
+	}
+
+	return archive, nil
+}
+
+// archiveAttribute returns the attribute holding the archive to restore.
+func (m TeleporterRestoreResourceModel) archiveAttribute() path.Path {
+	if !m.ArchivePath.IsNull() {
+		return path.Root("archive_path")
+	}
+
+	return path.Root("archive_base64")
+}
+
+// Create restores the archive.
+func (r *TeleporterRestoreResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan TeleporterRestoreResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	archive, err := plan.archive()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(plan.archiveAttribute(), "Invalid Teleporter Archive", err.Error())
+		return
+	}
+
+	var sections []string
+	resp.Diagnostics.Append(plan.Sections.ElementsAs(ctx, &sections, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.SHA256 = types.StringValue(teleporterChecksum(archive))
+	ctx = tflog.SetField(ctx, "sha256", plan.SHA256.ValueString())
+	ctx = tflog.SetField(ctx, "sections", sections)
+
+	files, err := r.client.RestoreTeleporter(ctx, archive, sections)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Restoring Pihole Teleporter Archive",
+			"Could not restore the Teleporter archive, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	plan.Files, diags = types.ListValueFrom(ctx, types.StringType, files)
+	resp.Diagnostics.Append(diags...)
+	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read keeps the state: a restore is not a record that can be read back.
+func (r *TeleporterRestoreResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+}
+
+func (r *TeleporterRestoreResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Update function will never be triggered as any change restores again
+}
+
+// Delete forgets the restore, leaving the restored configuration in Pihole.
+func (r *TeleporterRestoreResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	tflog.Info(ctx, "Removing the Teleporter restore from the state, the restored configuration is left in Pihole")
+}
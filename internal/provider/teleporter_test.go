@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClientTeleporter(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakePiholeV6(t)
+	fake.teleporter = []byte("PK backup")
+	client := fake.client()
+
+	archive, err := client.DownloadTeleporter(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(archive, fake.teleporter) {
+		t.Fatalf("expected the archive %q, got %q", fake.teleporter, archive)
+	}
+
+	files, err := client.RestoreTeleporter(ctx, []byte("PK golden"), []string{"adlists", "config"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected the restored files, got %v", files)
+	}
+	if string(fake.teleporter) != "PK golden" {
+		t.Errorf("expected the archive to be uploaded, got %q", fake.teleporter)
+	}
+	if !reflect.DeepEqual(fake.teleporterImport, newTeleporterImport([]string{"adlists", "config"})) {
+		t.Errorf("expected only the selected sections to be imported, got %+v", fake.teleporterImport)
+	}
+}
+
+func TestClientTeleporterRequiresV6(t *testing.T) {
+	client := newFakePihole(t).client()
+
+	_, err := client.DownloadTeleporter(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "requires Pihole v6") {
+		t.Fatalf("expected an error on Pihole v5, got: %v", err)
+	}
+}
+
+func TestNewTeleporterImport(t *testing.T) {
+	flags := newTeleporterImport([]string{"adlists", "groups", "dhcp_leases"})
+
+	if !flags.Gravity.Adlist || !flags.Gravity.AdlistByGroup || !flags.Gravity.Group || !flags.DHCPLeases {
+		t.Errorf("expected the selected sections to be imported, got %+v", flags)
+	}
+	if flags.Config || flags.Gravity.Domainlist || flags.Gravity.Client {
+		t.Errorf("expected the other sections not to be imported, got %+v", flags)
+	}
+
+	if names := teleporterSectionNames(); len(names) != 6 || names[0] != "adlists" {
+		t.Errorf("unexpected section names %v", names)
+	}
+}
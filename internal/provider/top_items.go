@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of top items ranked by Pihole.
+const (
+	topDomains        = "domains"
+	topBlockedDomains = "blocked_domains"
+	topClients        = "clients"
+	topUpstreams      = "upstreams"
+)
+
+// topItem is a domain, client or upstream server ranked by its number of
+// queries. Name is the domain, or the host name of clients and upstreams.
+type topItem struct {
+	Name string
+	IP   string
+	// Port is the port of upstream servers, -1 when not known.
+	Port  int64
+	Count int64
+}
+
+// GetTopItems returns the count items of the given kind with the most
+// queries, most queried first.
+func (c *piholeClient) GetTopItems(ctx context.Context, kind string, count int) ([]topItem, error) {
+	items, err := c.api.GetTopItems(ctx, kind, count)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].Name < items[j].Name
+	})
+	if count > 0 && len(items) > count {
+		items = items[:count]
+	}
+
+	return items, nil
+}
+
+// splitV5Source splits the "name|ip" sources of the v5 API, or their bare IP,
+// whose name and IP may be followed by #port.
+func splitV5Source(source string) topItem {
+	item := topItem{Port: -1}
+	item.Name, item.IP, _ = strings.Cut(source, "|")
+	if item.IP == "" {
+		item.Name, item.IP = "", item.Name
+	}
+	if ip, port, found := strings.Cut(item.IP, "#"); found {
+		if number, err := strconv.ParseInt(port, 10, 64); err == nil {
+			item.IP, item.Port = ip, number
+		}
+	}
+	item.Name, _, _ = strings.Cut(item.Name, "#")
+
+	return item
+}
+
+// GetTopItems returns the top items of the v5 API, whose upstream servers are
+// ranked by their share of the queries, turned into a number of queries.
+func (a *v5API) GetTopItems(ctx context.Context, kind string, count int) ([]topItem, error) {
+	auth := a.base.APIKey
+	var items []topItem
+
+	switch kind {
+	case topDomains, topBlockedDomains:
+		var answer struct {
+			TopQueries map[string]int64 `json:"top_queries"`
+			TopAds     map[string]int64 `json:"top_ads"`
+		}
+		if err := a.getJSON(ctx, url.Values{"topItems": {strconv.Itoa(count)}, "auth": {auth}}, &answer); err != nil {
+			return nil, err
+		}
+		domains := answer.TopQueries
+		if kind == topBlockedDomains {
+			domains = answer.TopAds
+		}
+		for domain, queries := range domains {
+			items = append(items, topItem{Name: domain, Port: -1, Count: queries})
+		}
+
+	case topClients:
+		var answer struct {
+			TopSources map[string]int64 `json:"top_sources"`
+		}
+		if err := a.getJSON(ctx, url.Values{"topClients": {strconv.Itoa(count)}, "auth": {auth}}, &answer); err != nil {
+			return nil, err
+		}
+		for source, queries := range answer.TopSources {
+			item := splitV5Source(source)
+			item.Count = queries
+			items = append(items, item)
+		}
+
+	case topUpstreams:
+		var answer struct {
+			ForwardDestinations map[string]float64 `json:"forward_destinations"`
+		}
+		if err := a.getJSON(ctx, url.Values{"getForwardDestinations": {""}, "auth": {auth}}, &answer); err != nil {
+			return nil, err
+		}
+		var summary struct {
+			Queries int64 `json:"dns_queries_today"`
+		}
+		if err := a.getJSON(ctx, url.Values{"summaryRaw": {""}, "auth": {auth}}, &summary); err != nil {
+			return nil, err
+		}
+		for destination, percentage := range answer.ForwardDestinations {
+			// The pseudo servers are named as in the v6 API
+			item := splitV5Source(destination)
+			switch item.IP {
+			case "blocked":
+				item.Name, item.IP = "blocklist", "blocklist"
+			case "cached":
+				item.Name, item.IP = "cache", "cache"
+			}
+			item.Count = int64(math.Round(percentage * float64(summary.Queries) / 100))
+			items = append(items, item)
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown top items %q", kind)
+	}
+
+	return items, nil
+}
+
+// GetTopItems returns the top items of the v6 statistics.
+func (a *v6API) GetTopItems(ctx context.Context, kind string, count int) ([]topItem, error) {
+	var answer struct {
+		Domains []struct {
+			Domain string `json:"domain"`
+			Count  int64  `json:"count"`
+		} `json:"domains"`
+		Clients []struct {
+			IP    string `json:"ip"`
+			Name  string `json:"name"`
+			Count int64  `json:"count"`
+		} `json:"clients"`
+		Upstreams []struct {
+			IP    string `json:"ip"`
+			Name  string `json:"name"`
+			Port  int64  `json:"port"`
+			Count int64  `json:"count"`
+		} `json:"upstreams"`
+	}
+
+	params := url.Values{"count": {strconv.Itoa(count)}}
+	var path string
+	switch kind {
+	case topDomains:
+		path = "/stats/top_domains"
+	case topBlockedDomains:
+		path = "/stats/top_domains"
+		params.Set("blocked", "true")
+	case topClients:
+		path = "/stats/top_clients"
+	case topUpstreams:
+		path, params = "/stats/upstreams", url.Values{}
+	default:
+		return nil, fmt.Errorf("unknown top items %q", kind)
+	}
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	if err := a.do(ctx, http.MethodGet, path, nil, &answer); err != nil {
+		return nil, err
+	}
+
+	var items []topItem
+	for _, domain := range answer.Domains {
+		items = append(items, topItem{Name: domain.Domain, Port: -1, Count: domain.Count})
+	}
+	for _, client := range answer.Clients {
+		items = append(items, topItem{Name: client.Name, IP: client.IP, Port: -1, Count: client.Count})
+	}
+	for _, upstream := range answer.Upstreams {
+		items = append(items, topItem{Name: upstream.Name, IP: upstream.IP, Port: upstream.Port, Count: upstream.Count})
+	}
+
+	return items, nil
+}
@@ -0,0 +1,241 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &TopItemsDataSource{}
+	_ datasource.DataSourceWithConfigure = &TopItemsDataSource{}
+)
+
+// defaultTopItemsCount is the number of items returned when count is not set.
+const defaultTopItemsCount = 10
+
+// NewTopDomainsDataSource is a helper function to simplify the provider implementation.
+func NewTopDomainsDataSource() datasource.DataSource {
+	return &TopItemsDataSource{
+		kind:        topDomains,
+		typeName:    "_top_domains",
+		description: "Domains permitted by Pihole with the most queries, most queried first.",
+		list:        "domains",
+	}
+}
+
+// NewTopBlockedDomainsDataSource is a helper function to simplify the provider implementation.
+func NewTopBlockedDomainsDataSource() datasource.DataSource {
+	return &TopItemsDataSource{
+		kind:        topBlockedDomains,
+		typeName:    "_top_blocked_domains",
+		description: "Domains blocked by Pihole with the most queries, most queried first.",
+		list:        "domains",
+	}
+}
+
+// NewTopClientsDataSource is a helper function to simplify the provider implementation.
+func NewTopClientsDataSource() datasource.DataSource {
+	return &TopItemsDataSource{
+		kind:        topClients,
+		typeName:    "_top_clients",
+		description: "Clients sending the most queries to Pihole, most active first.",
+		list:        "clients",
+	}
+}
+
+// NewUpstreamsDataSource is a helper function to simplify the provider implementation.
+func NewUpstreamsDataSource() datasource.DataSource {
+	return &TopItemsDataSource{
+		kind:     topUpstreams,
+		typeName: "_upstreams",
+		description: "Upstream servers answering the most queries for Pihole, most used first, including the " +
+			"blocklist and cache pseudo servers. Pihole v5 only reports the share of the queries of each server, " +
+			"turned into a number of queries.",
+		list: "upstreams",
+	}
+}
+
+// TopItemsDataSource is the data source implementation of the domains,
+// blocked domains, clients or upstream servers with the most queries.
+type TopItemsDataSource struct {
+	client *piholeClient
+
+	kind        string
+	typeName    string
+	description string
+	// list is the attribute listing the items.
+	list string
+}
+
+// TopDomainModel maps a domain with its number of queries.
+type TopDomainModel struct {
+	Domain types.String `tfsdk:"domain"`
+	Count  types.Int64  `tfsdk:"count"`
+}
+
+// TopClientModel maps a client with its number of queries.
+type TopClientModel struct {
+	IP    types.String `tfsdk:"ip"`
+	Name  types.String `tfsdk:"name"`
+	Count types.Int64  `tfsdk:"count"`
+}
+
+// UpstreamModel maps an upstream server with its number of queries.
+type UpstreamModel struct {
+	IP    types.String `tfsdk:"ip"`
+	Name  types.String `tfsdk:"name"`
+	Port  types.Int64  `tfsdk:"port"`
+	Count types.Int64  `tfsdk:"count"`
+}
+
+// Metadata returns the data source type name.
+func (d *TopItemsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + d.typeName
+}
+
+// Schema defines the schema for the data source.
+func (d *TopItemsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	count := schema.Int64Attribute{
+		Description: "Number of queries.",
+		Computed:    true,
+	}
+	ip := schema.StringAttribute{
+		Description: "IP address.",
+		Computed:    true,
+	}
+	name := schema.StringAttribute{
+		Description: "Host name, empty when not known.",
+		Computed:    true,
+	}
+
+	var item map[string]schema.Attribute
+	switch d.kind {
+	case topDomains, topBlockedDomains:
+		item = map[string]schema.Attribute{
+			"domain": schema.StringAttribute{
+				Description: "Domain queried.",
+				Computed:    true,
+			},
+			"count": count,
+		}
+	case topClients:
+		item = map[string]schema.Attribute{"ip": ip, "name": name, "count": count}
+	case topUpstreams:
+		item = map[string]schema.Attribute{
+			"ip":   ip,
+			"name": name,
+			"port": schema.Int64Attribute{
+				Description: "Port, -1 for the blocklist and cache pseudo servers or when not known.",
+				Computed:    true,
+			},
+			"count": count,
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		Description: d.description,
+		Attributes: map[string]schema.Attribute{
+			"count": schema.Int64Attribute{
+				Description: fmt.Sprintf("Maximum number of %s returned. Defaults to %d.", d.list, defaultTopItemsCount),
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64RangeValidator{min: 1, max: 1000},
+				},
+			},
+			d.list: schema.ListNestedAttribute{
+				Description: fmt.Sprintf("The %s, by decreasing number of queries.", d.list),
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: item,
+				},
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *TopItemsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*piholeClient)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *piholeClient, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+// Read refreshes the items from Pihole statistics.
+func (d *TopItemsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var count types.Int64
+	diags := req.Config.GetAttribute(ctx, path.Root("count"), &count)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	limit := defaultTopItemsCount
+	if !count.IsNull() {
+		limit = int(count.ValueInt64())
+	}
+
+	items, err := d.client.GetTopItems(ctx, d.kind, limit)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Pihole Statistics",
+			fmt.Sprintf("Could not read the top %s: %s", d.list, err),
+		)
+		return
+	}
+
+	var list interface{}
+	switch d.kind {
+	case topDomains, topBlockedDomains:
+		domains := make([]TopDomainModel, 0, len(items))
+		for _, item := range items {
+			domains = append(domains, TopDomainModel{Domain: types.StringValue(item.Name), Count: types.Int64Value(item.Count)})
+		}
+		list = domains
+	case topClients:
+		clients := make([]TopClientModel, 0, len(items))
+		for _, item := range items {
+			clients = append(clients, TopClientModel{
+				IP:    types.StringValue(item.IP),
+				Name:  types.StringValue(item.Name),
+				Count: types.Int64Value(item.Count),
+			})
+		}
+		list = clients
+	case topUpstreams:
+		upstreams := make([]UpstreamModel, 0, len(items))
+		for _, item := range items {
+			upstreams = append(upstreams, UpstreamModel{
+				IP:    types.StringValue(item.IP),
+				Name:  types.StringValue(item.Name),
+				Port:  types.Int64Value(item.Port),
+				Count: types.Int64Value(item.Count),
+			})
+		}
+		list = upstreams
+	}
+
+	diags = resp.State.SetAttribute(ctx, path.Root("count"), count)
+	resp.Diagnostics.Append(diags...)
+	diags = resp.State.SetAttribute(ctx, path.Root(d.list), list)
+	resp.Diagnostics.Append(diags...)
+}
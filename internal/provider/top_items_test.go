@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClientTopItems(t *testing.T) {
+	tests := map[string][]topItem{
+		topDomains: {
+			{Name: "example.com", Port: -1, Count: 1},
+			{Name: "www.example.com", Port: -1, Count: 1},
+		},
+		topBlockedDomains: {
+			{Name: "ads.example.net", Port: -1, Count: 1},
+			{Name: "example.com", Port: -1, Count: 1},
+		},
+		topClients: {
+			{Name: "laptop.lan", IP: "192.168.1.10", Port: -1, Count: 3},
+			{Name: "", IP: "192.168.1.20", Port: -1, Count: 1},
+		},
+		topUpstreams: {
+			{Name: "blocklist", IP: "blocklist", Port: -1, Count: 2},
+			{Name: "9.9.9.9", IP: "9.9.9.9", Port: 53, Count: 1},
+			{Name: "cache", IP: "cache", Port: -1, Count: 1},
+		},
+	}
+
+	for _, v6 := range []bool{false, true} {
+		fake := newFakePihole(t)
+		fake.v6 = v6
+		fake.queries = fakeQueries(time.Now())
+		client := fake.client()
+
+		for kind, expected := range tests {
+			items, err := client.GetTopItems(context.Background(), kind, 10)
+			if err != nil {
+				t.Fatalf("v6=%t %s: %v", v6, kind, err)
+			}
+			if !reflect.DeepEqual(items, expected) {
+				t.Errorf("v6=%t %s: expected %+v, got %+v", v6, kind, expected, items)
+			}
+		}
+
+		items, err := client.GetTopItems(context.Background(), topUpstreams, 1)
+		if err != nil || len(items) != 1 || items[0].Name != "blocklist" {
+			t.Errorf("v6=%t: expected the most used upstream only, got %+v, %v", v6, items, err)
+		}
+	}
+}
+
+func TestSplitV5Source(t *testing.T) {
+	for source, expected := range map[string]topItem{
+		"laptop.lan|192.168.1.10":     {Name: "laptop.lan", IP: "192.168.1.10", Port: -1},
+		"192.168.1.20":                {IP: "192.168.1.20", Port: -1},
+		"dns.quad9.net#53|9.9.9.9#53": {Name: "dns.quad9.net", IP: "9.9.9.9", Port: 53},
+	} {
+		if item := splitV5Source(source); item != expected {
+			t.Errorf("%s: expected %+v, got %+v", source, expected, item)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// defaultRequestTimeout matches the timeout used by pihole.NewClient.
+const defaultRequestTimeout = 10 * time.Second
+
+// transportConfig holds the HTTP settings applied to every Pihole API call.
+type transportConfig struct {
+	Timeout            time.Duration
+	CACertFile         string
+	CACertPEM          string
+	InsecureSkipVerify bool
+	ClientCertFile     string
+	ClientKeyFile      string
+	ClientCertPEM      string
+	ClientKeyPEM       string
+	ProxyURL           string
+}
+
+// newHTTPClient builds the HTTP client used to reach the Pihole API from the
+// provider transport settings.
+// An error is returned if a certificate or the proxy URL cannot be loaded.
+func newHTTPClient(config transportConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	// Trust the custom CA on top of the system pool
+	if config.CACertFile != "" || config.CACertPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if config.CACertFile != "" {
+			pem, err := os.ReadFile(config.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading CA certificate file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no PEM certificate found in %s", config.CACertFile)
+			}
+		}
+
+		if config.CACertPEM != "" && !pool.AppendCertsFromPEM([]byte(config.CACertPEM)) {
+			return nil, fmt.Errorf("no PEM certificate found in ca_cert_pem")
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	// Present a client certificate for mTLS
+	certPEM, keyPEM := []byte(config.ClientCertPEM), []byte(config.ClientKeyPEM)
+	if config.ClientCertFile != "" {
+		pem, err := os.ReadFile(config.ClientCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading client certificate file: %w", err)
+		}
+		certPEM = pem
+	}
+	if config.ClientKeyFile != "" {
+		pem, err := os.ReadFile(config.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading client key file: %w", err)
+		}
+		keyPEM = pem
+	}
+	if len(certPEM) != 0 || len(keyPEM) != 0 {
+		if len(certPEM) == 0 || len(keyPEM) == 0 {
+			return nil, fmt.Errorf("a client certificate requires both a certificate and a private key")
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	// Route requests through an explicit proxy, otherwise honour the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	if config.ProxyURL != "" {
+		proxy, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+
+	return &http.Client{
+		Transport: &loggingTransport{next: transport},
+		Timeout:   timeout,
+	}, nil
+}
@@ -0,0 +1,218 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ validator.String = hostnameValidator{}
+	_ validator.String = ipAddressValidator{}
+	_ validator.String = cnameTargetValidator{}
+	_ validator.String = oneOfValidator{}
+	_ validator.Set    = oneOfValidator{}
+	_ validator.Int64  = int64RangeValidator{}
+)
+
+// isValidHostname reports whether s is a valid RFC 1123 hostname: dot
+// separated labels of 1 to 63 letters, digits or hyphens, not starting or
+// ending with a hyphen, for a total of at most 253 characters.
+func isValidHostname(s string) bool {
+	if len(s) == 0 || len(s) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(s, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return false
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			switch {
+			case c >= 'a' && c <= 'z':
+			case c >= 'A' && c <= 'Z':
+			case c >= '0' && c <= '9':
+			case c == '-':
+			default:
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// hostnameValidator checks that a string attribute is a valid RFC 1123 hostname.
+type hostnameValidator struct{}
+
+func (v hostnameValidator) Description(_ context.Context) string {
+	return "value must be a valid RFC 1123 hostname"
+}
+
+func (v hostnameValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v hostnameValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	if !isValidHostname(value) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Hostname",
+			fmt.Sprintf("%q is not a valid hostname. Hostnames are made of dot separated labels of 1 to 63 letters, digits "+
+				"or hyphens, must not start or end a label with a hyphen and must be at most 253 characters long.", value),
+		)
+	}
+}
+
+// ipAddressValidator checks that a string attribute is an IPv4 or IPv6 address.
+type ipAddressValidator struct{}
+
+func (v ipAddressValidator) Description(_ context.Context) string {
+	return "value must be a valid IPv4 or IPv6 address"
+}
+
+func (v ipAddressValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v ipAddressValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	if net.ParseIP(value) == nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid IP Address",
+			fmt.Sprintf("%q is not a valid IPv4 or IPv6 address.", value),
+		)
+	}
+}
+
+// cnameTargetValidator checks that a CNAME target does not point back to the
+// alias declared in the domain attribute of the same resource.
+type cnameTargetValidator struct{}
+
+func (v cnameTargetValidator) Description(_ context.Context) string {
+	return "value must differ from the CNAME alias"
+}
+
+func (v cnameTargetValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v cnameTargetValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	var domain types.String
+	diags := req.Config.GetAttribute(ctx, path.Root("domain"), &domain)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || domain.IsNull() || domain.IsUnknown() {
+		return
+	}
+
+	if strings.EqualFold(domain.ValueString(), req.ConfigValue.ValueString()) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid CNAME Target",
+			fmt.Sprintf("The CNAME %q cannot target itself. Set target to a locally managed DNS record.", domain.ValueString()),
+		)
+	}
+}
+
+// oneOfValidator checks that a string attribute, or each element of a set of
+// strings, is one of the given values.
+type oneOfValidator struct {
+	values []string
+}
+
+func (v oneOfValidator) Description(_ context.Context) string {
+	return "value must be one of " + strings.Join(v.values, ", ")
+}
+
+func (v oneOfValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v oneOfValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	v.validate(req.Path, req.ConfigValue.ValueString(), &resp.Diagnostics)
+}
+
+func (v oneOfValidator) ValidateSet(ctx context.Context, req validator.SetRequest, resp *validator.SetResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	for _, element := range req.ConfigValue.Elements() {
+		value, ok := element.(types.String)
+		if !ok || value.IsNull() || value.IsUnknown() {
+			continue
+		}
+		v.validate(req.Path.AtSetValue(value), value.ValueString(), &resp.Diagnostics)
+	}
+}
+
+func (v oneOfValidator) validate(p path.Path, value string, diags *diag.Diagnostics) {
+	for _, allowed := range v.values {
+		if value == allowed {
+			return
+		}
+	}
+
+	diags.AddAttributeError(
+		p,
+		"Invalid Value",
+		fmt.Sprintf("%q is not supported, use one of %s.", value, strings.Join(v.values, ", ")),
+	)
+}
+
+// int64RangeValidator checks that an integer attribute is between min and
+// max, inclusive.
+type int64RangeValidator struct {
+	min int64
+	max int64
+}
+
+func (v int64RangeValidator) Description(_ context.Context) string {
+	return fmt.Sprintf("value must be between %d and %d", v.min, v.max)
+}
+
+func (v int64RangeValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v int64RangeValidator) ValidateInt64(ctx context.Context, req validator.Int64Request, resp *validator.Int64Response) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if value := req.ConfigValue.ValueInt64(); value < v.min || value > v.max {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Value",
+			fmt.Sprintf("%d is out of range, use a value between %d and %d.", value, v.min, v.max),
+		)
+	}
+}
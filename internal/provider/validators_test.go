@@ -0,0 +1,142 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestHostnameValidator(t *testing.T) {
+	tests := map[string]bool{
+		"test.example.com":               true,
+		"host":                           true,
+		"my-host01.lan":                  true,
+		"":                               false,
+		"has space.example.com":          false,
+		"-leading.example.com":           false,
+		"trailing-.example.com":          false,
+		"double..dot.com":                false,
+		"under_score.example.com":        false,
+		"1.1.1":                          true,
+		strings.Repeat("a", 64) + ".com": false,
+	}
+
+	for value, valid := range tests {
+		req := validator.StringRequest{
+			Path:        path.Root("domain"),
+			ConfigValue: types.StringValue(value),
+		}
+		resp := &validator.StringResponse{}
+
+		hostnameValidator{}.ValidateString(context.Background(), req, resp)
+
+		if resp.Diagnostics.HasError() == valid {
+			t.Errorf("hostname %q: expected valid=%t, got diagnostics %v", value, valid, resp.Diagnostics)
+		}
+	}
+}
+
+func TestIpAddressValidator(t *testing.T) {
+	tests := map[string]bool{
+		"1.2.3.4":        true,
+		"fd00::1":        true,
+		"::ffff:1.2.3.4": true,
+		"1.1.1":          false,
+		"256.1.1.1":      false,
+		"example.com":    false,
+		"":               false,
+	}
+
+	for value, valid := range tests {
+		req := validator.StringRequest{
+			Path:        path.Root("ip"),
+			ConfigValue: types.StringValue(value),
+		}
+		resp := &validator.StringResponse{}
+
+		ipAddressValidator{}.ValidateString(context.Background(), req, resp)
+
+		if resp.Diagnostics.HasError() == valid {
+			t.Errorf("ip %q: expected valid=%t, got diagnostics %v", value, valid, resp.Diagnostics)
+		}
+	}
+}
+
+func TestCnameTargetValidator(t *testing.T) {
+	ctx := context.Background()
+
+	schemaResp := &resource.SchemaResponse{}
+	NewCnameResource().Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	tests := map[string]struct {
+		domain string
+		target string
+		valid  bool
+	}{
+		"local target": {domain: "alias.example.com", target: "host.example.com", valid: true},
+		"self target":  {domain: "alias.example.com", target: "alias.example.com", valid: false},
+		"case folding": {domain: "Alias.example.com", target: "alias.EXAMPLE.com", valid: false},
+	}
+
+	for name, test := range tests {
+		config := tfsdk.Config{
+			Schema: schemaResp.Schema,
+			Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), map[string]tftypes.Value{
+				"last_updated": tftypes.NewValue(tftypes.String, nil),
+				"domain":       tftypes.NewValue(tftypes.String, test.domain),
+				"target":       tftypes.NewValue(tftypes.String, test.target),
+			}),
+		}
+		req := validator.StringRequest{
+			Path:        path.Root("target"),
+			ConfigValue: types.StringValue(test.target),
+			Config:      config,
+		}
+		resp := &validator.StringResponse{}
+
+		cnameTargetValidator{}.ValidateString(ctx, req, resp)
+
+		if resp.Diagnostics.HasError() == test.valid {
+			t.Errorf("%s: expected valid=%t, got diagnostics %v", name, test.valid, resp.Diagnostics)
+		}
+	}
+}
+
+func TestOneOfValidator(t *testing.T) {
+	v := oneOfValidator{values: []string{"adlists", "domains"}}
+
+	for value, valid := range map[string]bool{"adlists": true, "domains": true, "Adlists": false, "": false} {
+		resp := &validator.StringResponse{}
+		v.ValidateString(context.Background(), validator.StringRequest{Path: path.Root("type"), ConfigValue: types.StringValue(value)}, resp)
+		if resp.Diagnostics.HasError() == valid {
+			t.Errorf("%q: expected valid=%t, got %v", value, valid, resp.Diagnostics)
+		}
+	}
+
+	set := types.SetValueMust(types.StringType, []attr.Value{types.StringValue("adlists"), types.StringValue("groups")})
+	resp := &validator.SetResponse{}
+	v.ValidateSet(context.Background(), validator.SetRequest{Path: path.Root("sections"), ConfigValue: set}, resp)
+	if resp.Diagnostics.ErrorsCount() != 1 {
+		t.Fatalf("expected an error for the unknown set element, got %v", resp.Diagnostics)
+	}
+}
+
+func TestInt64RangeValidator(t *testing.T) {
+	v := int64RangeValidator{min: 0, max: 3}
+
+	for value, valid := range map[int64]bool{0: true, 3: true, -1: false, 4: false} {
+		resp := &validator.Int64Response{}
+		v.ValidateInt64(context.Background(), validator.Int64Request{Path: path.Root("privacy_level"), ConfigValue: types.Int64Value(value)}, resp)
+		if resp.Diagnostics.HasError() == valid {
+			t.Errorf("%d: expected valid=%t, got %v", value, valid, resp.Diagnostics)
+		}
+	}
+}
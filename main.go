@@ -4,6 +4,8 @@ import (
 	"context"
 	"flag"
 	"log"
+	"os"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 
@@ -29,12 +31,30 @@ var (
 	// https://goreleaser.com/cookbooks/using-main.version/
 )
 
+// closeSessionsTimeout bounds the logout of the Pihole sessions once the
+// provider stops.
+const closeSessionsTimeout = 2 * time.Second
+
 func main() {
-	var debug bool
+	var debug, generateConfig bool
 
 	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
+	flag.BoolVar(&generateConfig, "generate-config", false, "print the resource and import blocks adopting the records, adlists and group members of "+
+		"the Pihole set by the PIHOLE_API_URL, PIHOLE_TOKEN and PIHOLE_PASSWORD environment variables, then exit")
 	flag.Parse()
 
+	if generateConfig {
+		err := provider.GenerateConfig(context.Background(), os.Stdout, provider.GenerateConfigSettings{
+			URL:      os.Getenv("PIHOLE_API_URL"),
+			Token:    os.Getenv("PIHOLE_TOKEN"),
+			Password: os.Getenv("PIHOLE_PASSWORD"),
+		})
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
 	opts := providerserver.ServeOpts{
 		// NOTE: This is not a typical Terraform Registry provider address,
 		// such as registry.terraform.io/hashicorp/hashicups. This specific
@@ -47,6 +67,12 @@ func main() {
 
 	err := providerserver.Serve(context.Background(), provider.New(version), opts)
 
+	// Log the Pihole sessions out rather than leaving them to expire, Terraform
+	// killing the provider shortly after asking it to stop
+	ctx, cancel := context.WithTimeout(context.Background(), closeSessionsTimeout)
+	provider.CloseSessions(ctx)
+	cancel()
+
 	if err != nil {
 		log.Fatal(err.Error())
 	}